@@ -1,11 +1,14 @@
 package app
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/forzeyy/avito-autumn/internal/config"
 	"github.com/forzeyy/avito-autumn/internal/database"
+	"github.com/forzeyy/avito-autumn/internal/repos"
 	"github.com/forzeyy/avito-autumn/internal/routes"
+	"github.com/forzeyy/avito-autumn/internal/services"
 	"github.com/labstack/echo/v4"
 )
 
@@ -20,8 +23,17 @@ func Run(cfg *config.Config) error {
 	}
 	defer conn.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prService := services.NewPRService(
+		repos.NewPRRepo(conn), repos.NewUserRepo(conn), repos.NewTeamRepo(conn),
+		repos.NewCodeOwnersRepo(conn), repos.NewReviewRepo(conn), repos.NewReviewerPoolRepo(conn),
+	)
+	go services.NewSLAWorker(prService, cfg.SLACheckInterval).Run(ctx)
+
 	e := echo.New()
-	routes.InitRoutes(e, conn)
+	routes.InitRoutes(e, conn, prService)
 	e.Logger.Fatal(e.Start(":8080"))
 
 	return nil
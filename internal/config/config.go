@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +14,8 @@ type Config struct {
 	DBHost     string
 	DBPort     string
 	DBName     string
+
+	SLACheckInterval time.Duration
 }
 
 func LoadConfig() *Config {
@@ -26,5 +29,20 @@ func LoadConfig() *Config {
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		DBName:     os.Getenv("DB_NAME"),
+
+		SLACheckInterval: durationFromEnv("SLA_CHECK_INTERVAL", time.Minute),
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		fmt.Printf("некорректное значение %s: %q, используется %v\n", key, value, fallback)
+		return fallback
 	}
+	return d
 }
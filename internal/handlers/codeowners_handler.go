@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CodeOwnersHandler interface {
+	UploadCodeOwners(c echo.Context) error
+	GetCodeOwners(c echo.Context) error
+}
+
+type codeOwnersHandler struct {
+	codeOwnersService services.CodeOwnersService
+}
+
+func NewCodeOwnersHandler(codeOwnersService services.CodeOwnersService) CodeOwnersHandler {
+	return &codeOwnersHandler{
+		codeOwnersService: codeOwnersService,
+	}
+}
+
+// codeOwnersScope определяет область CODEOWNERS: репозиторий, если он указан, иначе команда
+func codeOwnersScope(teamName, repository string) (models.CodeOwnersScope, string) {
+	if repository != "" {
+		return models.CodeOwnersScopeRepository, repository
+	}
+	return models.CodeOwnersScopeTeam, teamName
+}
+
+func (coh *codeOwnersHandler) UploadCodeOwners(c echo.Context) error {
+	var req struct {
+		TeamName   string `json:"team_name"`
+		Repository string `json:"repository"`
+		Content    string `json:"content"`
+		Required   bool   `json:"required"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	scope, name := codeOwnersScope(req.TeamName, req.Repository)
+	file, err := coh.codeOwnersService.UploadCodeOwners(c.Request().Context(), &models.CodeOwnersFile{
+		Scope:    scope,
+		Name:     name,
+		Content:  req.Content,
+		Required: req.Required,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "team_name or repository and a valid CODEOWNERS content are required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"codeowners": file,
+	})
+}
+
+func (coh *codeOwnersHandler) GetCodeOwners(c echo.Context) error {
+	scope, name := codeOwnersScope(c.QueryParam("team_name"), c.QueryParam("repository"))
+
+	file, err := coh.codeOwnersService.GetCodeOwners(c.Request().Context(), scope, name)
+	if err != nil {
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "team_name or repository is required",
+				},
+			})
+		}
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": map[string]string{
+				"code":    "NOT_FOUND",
+				"message": "CODEOWNERS not found",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"codeowners": file,
+	})
+}
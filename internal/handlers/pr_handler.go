@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/services"
@@ -12,6 +17,15 @@ type PRHandler interface {
 	CreatePR(c echo.Context) error
 	MergePR(c echo.Context) error
 	ReassignReviewer(c echo.Context) error
+	SubmitReview(c echo.Context) error
+	AddReviewer(c echo.Context) error
+	RemoveReviewer(c echo.Context) error
+	ClosePR(c echo.Context) error
+	ReopenPR(c echo.Context) error
+	MarkReady(c echo.Context) error
+	GetPR(c echo.Context) error
+	ListPRs(c echo.Context) error
+	ListUnderstaffed(c echo.Context) error
 }
 
 type prHandler struct {
@@ -25,7 +39,7 @@ func NewPRHandler(prService services.PRService) PRHandler {
 }
 
 func (prh *prHandler) CreatePR(c echo.Context) error {
-	var req models.PullRequestShort
+	var req models.CreatePRRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": map[string]string{
@@ -35,7 +49,7 @@ func (prh *prHandler) CreatePR(c echo.Context) error {
 		})
 	}
 
-	pr, err := prh.prService.CreatePR(c.Request().Context(), req.ID, req.Name, req.AuthorID)
+	pr, err := prh.prService.CreatePR(c.Request().Context(), &req)
 	if err != nil {
 		if err.Error() == "PR_EXISTS" {
 			return c.JSON(http.StatusConflict, echo.Map{
@@ -45,12 +59,44 @@ func (prh *prHandler) CreatePR(c echo.Context) error {
 				},
 			})
 		}
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "co_authors must not contain empty ids",
+				},
+			})
+		}
 		if err.Error() == "NOT_FOUND" {
 			return c.JSON(http.StatusNotFound, echo.Map{
 				"code":    "NOT_FOUND",
 				"message": "resource not found",
 			})
 		}
+		if err.Error() == "NO_CANDIDATE" {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "NO_CANDIDATE",
+					"message": "not enough active reviewers in team to satisfy min_reviewers",
+				},
+			})
+		}
+		if err.Error() == "CAPACITY_EXCEEDED" {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "CAPACITY_EXCEEDED",
+					"message": "all available reviewers in team are at their open review limit",
+				},
+			})
+		}
+		if err.Error() == "NO_CODEOWNER" {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "NO_CODEOWNER",
+					"message": "CODEOWNERS review is required but no active owner is available",
+				},
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"code":    "INTERNAL_ERROR",
 			"message": "internal server error",
@@ -62,7 +108,7 @@ func (prh *prHandler) CreatePR(c echo.Context) error {
 }
 
 func (prh *prHandler) MergePR(c echo.Context) error {
-	var req models.PullRequestShort
+	var req models.MergePRRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": map[string]string{
@@ -72,7 +118,7 @@ func (prh *prHandler) MergePR(c echo.Context) error {
 		})
 	}
 
-	pr, err := prh.prService.MergePR(c.Request().Context(), req.ID)
+	pr, err := prh.prService.MergePR(c.Request().Context(), req.ID, req.Force)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			return c.JSON(http.StatusNotFound, echo.Map{
@@ -80,6 +126,22 @@ func (prh *prHandler) MergePR(c echo.Context) error {
 				"message": "resource not found",
 			})
 		}
+		if err.Error() == "INVALID_TRANSITION" {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_TRANSITION",
+					"message": "only OPEN pull requests can be merged",
+				},
+			})
+		}
+		if err.Error() == "NOT_ENOUGH_APPROVALS" {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_ENOUGH_APPROVALS",
+					"message": "pull request does not have the required approvals, use force to merge anyway",
+				},
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": "internal server error",
 		})
@@ -93,6 +155,7 @@ func (prh *prHandler) ReassignReviewer(c echo.Context) error {
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
 		OldUserID     string `json:"old_user_id"`
+		NewUserID     string `json:"new_user_id,omitempty"`
 	}
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -107,6 +170,7 @@ func (prh *prHandler) ReassignReviewer(c echo.Context) error {
 		c.Request().Context(),
 		req.PullRequestID,
 		req.OldUserID,
+		req.NewUserID,
 	)
 	if err != nil {
 		errCode := err.Error()
@@ -116,10 +180,28 @@ func (prh *prHandler) ReassignReviewer(c echo.Context) error {
 			msg = "PR or user not found"
 		case "PR_MERGED":
 			msg = "cannot reassign on merged PR"
+		case "PR_CLOSED":
+			msg = "cannot reassign on closed PR"
+		case "PR_DRAFT":
+			msg = "draft PR has no reviewers yet"
 		case "NOT_ASSIGNED":
 			msg = "reviewer is not assigned to this PR"
 		case "NO_CANDIDATE":
 			msg = "no active replacement candidate in team"
+		case "CAPACITY_EXCEEDED":
+			msg = "all replacement candidates are at their open review limit"
+		case "REVIEWER_IS_AUTHOR":
+			msg = "new reviewer is the author of this PR"
+		case "USER_INACTIVE":
+			msg = "new reviewer is not active"
+		case "ALREADY_ASSIGNED":
+			msg = "new reviewer is already assigned to this PR"
+		case "NOT_TEAM_MEMBER":
+			msg = "new reviewer is not in the old reviewer's or the author's team"
+		case "USER_UNAVAILABLE":
+			msg = "new reviewer is out of office"
+		case "REVIEWER_AT_CAPACITY":
+			msg = "new reviewer is at their open review limit"
 		default:
 			msg = err.Error()
 		}
@@ -135,3 +217,353 @@ func (prh *prHandler) ReassignReviewer(c echo.Context) error {
 		"replaced_by": newID,
 	})
 }
+
+func (prh *prHandler) SubmitReview(c echo.Context) error {
+	var req models.Review
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	review, err := prh.prService.SubmitReview(c.Request().Context(), &req)
+	if err != nil {
+		errCode := err.Error()
+		status := http.StatusConflict
+		var msg string
+		switch errCode {
+		case "INVALID_INPUT":
+			status = http.StatusBadRequest
+			msg = "pull_request_id, reviewer_id and decision (APPROVED, CHANGES_REQUESTED, COMMENTED) are required"
+		case "NOT_FOUND":
+			status = http.StatusNotFound
+			msg = "resource not found"
+		case "PR_MERGED":
+			msg = "cannot review merged PR"
+		case "PR_CLOSED":
+			msg = "cannot review closed PR"
+		case "PR_DRAFT":
+			msg = "cannot review draft PR"
+		case "NOT_ASSIGNED":
+			msg = "reviewer is not assigned to this PR"
+		default:
+			return c.JSON(http.StatusInternalServerError, echo.Map{
+				"message": "internal server error",
+			})
+		}
+		return c.JSON(status, echo.Map{
+			"error": map[string]string{
+				"code":    errCode,
+				"message": msg,
+			},
+		})
+	}
+	return c.JSON(http.StatusCreated, echo.Map{
+		"review": review,
+	})
+}
+
+// reviewerChangeError переводит коды ошибок добавления и снятия ревьюера в ответ
+func reviewerChangeError(c echo.Context, err error) error {
+	errCode := err.Error()
+	status := http.StatusConflict
+	var msg string
+	switch errCode {
+	case "INVALID_INPUT":
+		status = http.StatusBadRequest
+		msg = "pull_request_id and user_id are required"
+	case "NOT_FOUND":
+		status = http.StatusNotFound
+		msg = "PR or user not found"
+	case "PR_MERGED":
+		msg = "cannot change reviewers on merged PR"
+	case "PR_CLOSED":
+		msg = "cannot change reviewers on closed PR"
+	case "PR_DRAFT":
+		msg = "reviewers are assigned when PR is marked ready"
+	case "REVIEWER_IS_AUTHOR":
+		msg = "author cannot review own PR"
+	case "USER_INACTIVE":
+		msg = "user is not active"
+	case "NOT_TEAM_MEMBER":
+		msg = "user is not a member of the author's team"
+	case "ALREADY_ASSIGNED":
+		msg = "user is already assigned to this PR"
+	case "USER_UNAVAILABLE":
+		msg = "user is out of office"
+	case "REVIEWER_AT_CAPACITY":
+		msg = "user is at their open review limit"
+	case "NOT_ASSIGNED":
+		msg = "reviewer is not assigned to this PR"
+	case "NO_CANDIDATE":
+		msg = "no active candidate in team"
+	case "CAPACITY_EXCEEDED":
+		msg = "all candidates are at their open review limit"
+	case "BELOW_MIN_REVIEWERS":
+		msg = "PR would have fewer reviewers than team min_reviewers"
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": "internal server error",
+		})
+	}
+	return c.JSON(status, echo.Map{
+		"error": map[string]string{
+			"code":    errCode,
+			"message": msg,
+		},
+	})
+}
+
+func (prh *prHandler) AddReviewer(c echo.Context) error {
+	var req models.ReviewerChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	pr, err := prh.prService.AddReviewer(c.Request().Context(), &req)
+	if err != nil {
+		return reviewerChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pr": pr,
+	})
+}
+
+func (prh *prHandler) RemoveReviewer(c echo.Context) error {
+	var req models.ReviewerChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	pr, err := prh.prService.RemoveReviewer(c.Request().Context(), &req)
+	if err != nil {
+		return reviewerChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pr": pr,
+	})
+}
+
+// transitionPR обрабатывает запросы смены статуса пулл реквеста
+func (prh *prHandler) transitionPR(c echo.Context, transition func(ctx context.Context, prID string) (*models.PullRequest, error)) error {
+	var req models.PullRequestShort
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	pr, err := transition(c.Request().Context(), req.ID)
+	if err != nil {
+		errCode := err.Error()
+		status := http.StatusConflict
+		var msg string
+		switch errCode {
+		case "INVALID_INPUT":
+			status = http.StatusBadRequest
+			msg = "pull_request_id is required"
+		case "NOT_FOUND":
+			status = http.StatusNotFound
+			msg = "PR or author not found"
+		case "PR_MERGED":
+			msg = "merged PR cannot change status"
+		case "INVALID_TRANSITION":
+			msg = "transition is not allowed: DRAFT -> OPEN (markReady), DRAFT/OPEN -> CLOSED (close), CLOSED -> OPEN (reopen)"
+		case "NO_CANDIDATE":
+			msg = "not enough active reviewers in team to satisfy min_reviewers"
+		case "CAPACITY_EXCEEDED":
+			msg = "all available reviewers in team are at their open review limit"
+		case "NO_CODEOWNER":
+			msg = "CODEOWNERS review is required but no active owner is available"
+		default:
+			return c.JSON(http.StatusInternalServerError, echo.Map{
+				"message": "internal server error",
+			})
+		}
+		return c.JSON(status, echo.Map{
+			"error": map[string]string{
+				"code":    errCode,
+				"message": msg,
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pr": pr,
+	})
+}
+
+func (prh *prHandler) ClosePR(c echo.Context) error {
+	return prh.transitionPR(c, prh.prService.ClosePR)
+}
+
+func (prh *prHandler) ReopenPR(c echo.Context) error {
+	return prh.transitionPR(c, prh.prService.ReopenPR)
+}
+
+func (prh *prHandler) MarkReady(c echo.Context) error {
+	return prh.transitionPR(c, prh.prService.MarkReady)
+}
+
+// statusesParam разбирает параметр status вида OPEN,MERGED
+func statusesParam(c echo.Context) []models.Status {
+	var statuses []models.Status
+	for _, status := range strings.Split(c.QueryParam("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses = append(statuses, models.Status(strings.ToUpper(status)))
+		}
+	}
+	return statuses
+}
+
+// timeParam принимает RFC3339 или дату. Дата в верхней границе диапазона
+// означает конец дня, чтобы граница включала весь день
+func timeParam(c echo.Context, name string, endOfDay bool) (*time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, errors.New(name + " must be RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Microsecond)
+	}
+	return &t, nil
+}
+
+func (prh *prHandler) GetPR(c echo.Context) error {
+	pr, err := prh.prService.GetPR(c.Request().Context(), c.QueryParam("pull_request_id"))
+	if err != nil {
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "pull_request_id is required",
+				},
+			})
+		}
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": map[string]string{
+				"code":    "NOT_FOUND",
+				"message": "PR not found",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pr": pr,
+	})
+}
+
+func (prh *prHandler) ListPRs(c echo.Context) error {
+	invalidInput := func(msg string) error {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": msg,
+			},
+		})
+	}
+
+	filter := models.PRListFilter{
+		Statuses:     statusesParam(c),
+		AuthorID:     c.QueryParam("author_id"),
+		ReviewerID:   c.QueryParam("reviewer_id"),
+		TeamName:     c.QueryParam("team_name"),
+		NameContains: c.QueryParam("name"),
+		SortBy:       models.PRSortField(c.QueryParam("sort")),
+	}
+
+	var err error
+	bounds := []struct {
+		name     string
+		dst      **time.Time
+		endOfDay bool
+	}{
+		{"created_from", &filter.CreatedFrom, false},
+		{"created_to", &filter.CreatedTo, true},
+		{"merged_from", &filter.MergedFrom, false},
+		{"merged_to", &filter.MergedTo, true},
+	}
+	for _, bound := range bounds {
+		if *bound.dst, err = timeParam(c, bound.name, bound.endOfDay); err != nil {
+			return invalidInput(err.Error())
+		}
+	}
+
+	switch strings.ToLower(c.QueryParam("order")) {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return invalidInput("order must be asc or desc")
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return invalidInput("limit must be a number")
+		}
+	}
+
+	page, err := prh.prService.ListPRs(c.Request().Context(), filter, c.QueryParam("cursor"))
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return invalidInput("check status, sort (created_at, merged_at, name), limit and date ranges")
+		case "INVALID_CURSOR":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_CURSOR",
+					"message": "cursor is malformed or was issued for another sort order",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": "internal server error",
+		})
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (prh *prHandler) ListUnderstaffed(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+
+	understaffed, err := prh.prService.ListUnderstaffed(c.Request().Context(), teamName)
+	if err != nil {
+		if err.Error() == "NOT_FOUND" {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pull_requests": understaffed,
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewerPoolHandler interface {
+	SavePool(c echo.Context) error
+	GetPool(c echo.Context) error
+	ListPools(c echo.Context) error
+	DeletePool(c echo.Context) error
+	AttachPool(c echo.Context) error
+	DetachPool(c echo.Context) error
+	GetTeamPools(c echo.Context) error
+}
+
+type reviewerPoolHandler struct {
+	poolService services.ReviewerPoolService
+}
+
+func NewReviewerPoolHandler(poolService services.ReviewerPoolService) ReviewerPoolHandler {
+	return &reviewerPoolHandler{
+		poolService: poolService,
+	}
+}
+
+// poolError отвечает на ошибки операций с пулами ревьюеров
+func poolError(c echo.Context, err error, invalidMsg, notFoundMsg string) error {
+	switch err.Error() {
+	case "INVALID_INPUT":
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": invalidMsg,
+			},
+		})
+	case "NOT_FOUND":
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": map[string]string{
+				"code":    "NOT_FOUND",
+				"message": notFoundMsg,
+			},
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": map[string]string{
+			"code":    "INTERNAL_ERROR",
+			"message": "internal server error",
+		},
+	})
+}
+
+func (rph *reviewerPoolHandler) SavePool(c echo.Context) error {
+	var req models.SavePoolRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	pool, err := rph.poolService.SavePool(c.Request().Context(), &req)
+	if err != nil {
+		return poolError(c, err, "pool_name is required, user_ids must not contain empty ids", "user not found")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pool": pool,
+	})
+}
+
+func (rph *reviewerPoolHandler) GetPool(c echo.Context) error {
+	pool, err := rph.poolService.GetPool(c.Request().Context(), c.QueryParam("pool_name"))
+	if err != nil {
+		return poolError(c, err, "pool_name is required", "pool not found")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pool": pool,
+	})
+}
+
+func (rph *reviewerPoolHandler) ListPools(c echo.Context) error {
+	pools, err := rph.poolService.ListPools(c.Request().Context())
+	if err != nil {
+		return poolError(c, err, "", "")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pools": pools,
+	})
+}
+
+func (rph *reviewerPoolHandler) DeletePool(c echo.Context) error {
+	var req struct {
+		PoolName string `json:"pool_name"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	err := rph.poolService.DeletePool(c.Request().Context(), req.PoolName)
+	if err != nil {
+		return poolError(c, err, "pool_name is required", "pool not found")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"pool_name": req.PoolName,
+	})
+}
+
+func (rph *reviewerPoolHandler) AttachPool(c echo.Context) error {
+	var req models.TeamPool
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	teamPools, err := rph.poolService.AttachPool(c.Request().Context(), &req)
+	if err != nil {
+		return poolError(c, err, "team_name, pool_name and mode SUPPLEMENTARY or FALLBACK are required", "team or pool not found")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team_name": req.TeamName,
+		"pools":     teamPools,
+	})
+}
+
+func (rph *reviewerPoolHandler) DetachPool(c echo.Context) error {
+	var req struct {
+		TeamName string `json:"team_name"`
+		PoolName string `json:"pool_name"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	teamPools, err := rph.poolService.DetachPool(c.Request().Context(), req.TeamName, req.PoolName)
+	if err != nil {
+		return poolError(c, err, "team_name and pool_name are required", "team not found or pool is not attached to it")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team_name": req.TeamName,
+		"pools":     teamPools,
+	})
+}
+
+func (rph *reviewerPoolHandler) GetTeamPools(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+
+	teamPools, err := rph.poolService.GetTeamPools(c.Request().Context(), teamName)
+	if err != nil {
+		return poolError(c, err, "team_name is required", "team not found")
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team_name": teamName,
+		"pools":     teamPools,
+	})
+}
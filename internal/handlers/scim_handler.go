@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMHandler обслуживает /scim/v2. Ответы и ошибки оформлены по RFC 7644, а не в формате
+// остального API, потому что их разбирают провайдеры идентификации
+type SCIMHandler interface {
+	ListUsers(c echo.Context) error
+	GetUser(c echo.Context) error
+	CreateUser(c echo.Context) error
+	ReplaceUser(c echo.Context) error
+	PatchUser(c echo.Context) error
+	DeleteUser(c echo.Context) error
+	ListGroups(c echo.Context) error
+	GetGroup(c echo.Context) error
+	CreateGroup(c echo.Context) error
+	ReplaceGroup(c echo.Context) error
+	PatchGroup(c echo.Context) error
+	DeleteGroup(c echo.Context) error
+}
+
+type scimHandler struct {
+	scimService services.SCIMService
+}
+
+func NewSCIMHandler(scimService services.SCIMService) SCIMHandler {
+	return &scimHandler{
+		scimService: scimService,
+	}
+}
+
+const scimDefaultCount = 100
+
+func scimJSON(c echo.Context, status int, body any) error {
+	c.Response().Header().Set(echo.HeaderContentType, "application/scim+json")
+	return c.JSON(status, body)
+}
+
+func scimError(c echo.Context, status int, scimType, detail string) error {
+	return scimJSON(c, status, models.SCIMError{
+		Schemas:  []string{models.SCIMErrorSchema},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
+
+func scimServiceError(c echo.Context, err error) error {
+	switch err.Error() {
+	case "INVALID_FILTER":
+		return scimError(c, http.StatusBadRequest, "invalidFilter", "filter is not supported")
+	case "INVALID_INPUT":
+		return scimError(c, http.StatusBadRequest, "invalidValue", "please check your input")
+	case "NOT_FOUND":
+		return scimError(c, http.StatusNotFound, "", "resource not found")
+	case "USER_EXISTS":
+		return scimError(c, http.StatusConflict, "uniqueness", "user already exists")
+	case "TEAM_EXISTS":
+		return scimError(c, http.StatusConflict, "uniqueness", "group already exists")
+	case "TEAM_HAS_OPEN_PRS":
+		return scimError(c, http.StatusConflict, "", "group has open pull requests")
+	}
+	return scimError(c, http.StatusInternalServerError, "", "internal server error")
+}
+
+// scimBody разбирает тело запроса вручную: Bind не знает тип application/scim+json
+func scimBody(c echo.Context, v any) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return scimError(c, http.StatusBadRequest, "invalidSyntax", "please check your input")
+	}
+	return nil
+}
+
+// scimPaging читает startIndex и count, по умолчанию первая страница из scimDefaultCount записей
+func scimPaging(c echo.Context) (int, int, bool) {
+	startIndex, count := 1, scimDefaultCount
+	if raw := c.QueryParam("startIndex"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, false
+		}
+		startIndex = value
+	}
+	if raw := c.QueryParam("count"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, 0, false
+		}
+		count = value
+	}
+	return startIndex, count, true
+}
+
+func (sh *scimHandler) ListUsers(c echo.Context) error {
+	startIndex, count, ok := scimPaging(c)
+	if !ok {
+		return scimError(c, http.StatusBadRequest, "invalidValue", "startIndex and count must be integers")
+	}
+
+	resp, err := sh.scimService.ListUsers(c.Request().Context(), c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, resp)
+}
+
+func (sh *scimHandler) GetUser(c echo.Context) error {
+	user, err := sh.scimService.GetUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, user)
+}
+
+func (sh *scimHandler) CreateUser(c echo.Context) error {
+	var req models.SCIMUser
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := sh.scimService.CreateUser(c.Request().Context(), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusCreated, user)
+}
+
+func (sh *scimHandler) ReplaceUser(c echo.Context) error {
+	var req models.SCIMUser
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := sh.scimService.ReplaceUser(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, user)
+}
+
+func (sh *scimHandler) PatchUser(c echo.Context) error {
+	var req models.SCIMPatchRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	user, err := sh.scimService.PatchUser(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, user)
+}
+
+func (sh *scimHandler) DeleteUser(c echo.Context) error {
+	if err := sh.scimService.DeleteUser(c.Request().Context(), c.Param("id")); err != nil {
+		return scimServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sh *scimHandler) ListGroups(c echo.Context) error {
+	startIndex, count, ok := scimPaging(c)
+	if !ok {
+		return scimError(c, http.StatusBadRequest, "invalidValue", "startIndex and count must be integers")
+	}
+
+	resp, err := sh.scimService.ListGroups(c.Request().Context(), c.QueryParam("filter"), startIndex, count)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, resp)
+}
+
+func (sh *scimHandler) GetGroup(c echo.Context) error {
+	group, err := sh.scimService.GetGroup(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, group)
+}
+
+func (sh *scimHandler) CreateGroup(c echo.Context) error {
+	var req models.SCIMGroup
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	group, err := sh.scimService.CreateGroup(c.Request().Context(), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusCreated, group)
+}
+
+func (sh *scimHandler) ReplaceGroup(c echo.Context) error {
+	var req models.SCIMGroup
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	group, err := sh.scimService.ReplaceGroup(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, group)
+}
+
+func (sh *scimHandler) PatchGroup(c echo.Context) error {
+	var req models.SCIMPatchRequest
+	if err := scimBody(c, &req); err != nil {
+		return err
+	}
+
+	group, err := sh.scimService.PatchGroup(c.Request().Context(), c.Param("id"), &req)
+	if err != nil {
+		return scimServiceError(c, err)
+	}
+	return scimJSON(c, http.StatusOK, group)
+}
+
+func (sh *scimHandler) DeleteGroup(c echo.Context) error {
+	if err := sh.scimService.DeleteGroup(c.Request().Context(), c.Param("id")); err != nil {
+		return scimServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/services"
@@ -11,6 +12,16 @@ import (
 type TeamHandler interface {
 	CreateTeam(c echo.Context) error
 	GetTeam(c echo.Context) error
+	GetTeamSettings(c echo.Context) error
+	UpdateTeamSettings(c echo.Context) error
+	DeactivateUsers(c echo.Context) error
+	AddMembers(c echo.Context) error
+	RemoveMembers(c echo.Context) error
+	MoveUser(c echo.Context) error
+	RenameTeam(c echo.Context) error
+	SetParentTeam(c echo.Context) error
+	DeleteTeam(c echo.Context) error
+	SyncTeam(c echo.Context) error
 }
 
 type teamHandler struct {
@@ -44,6 +55,14 @@ func (th *teamHandler) CreateTeam(c echo.Context) error {
 				},
 			})
 		}
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "unknown reviewer_strategy or parent_team",
+				},
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"error": map[string]string{
 				"code":    "INTERNAL_ERROR",
@@ -78,3 +97,302 @@ func (th *teamHandler) GetTeam(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, team)
 }
+
+func (th *teamHandler) GetTeamSettings(c echo.Context) error {
+	teamName := c.QueryParam("team_name")
+
+	settings, err := th.teamService.GetTeamSettings(c.Request().Context(), teamName)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "team_name is required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"settings": settings,
+	})
+}
+
+func (th *teamHandler) UpdateTeamSettings(c echo.Context) error {
+	var req models.TeamSettingsUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	settings, err := th.teamService.UpdateTeamSettings(c.Request().Context(), &req)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "settings must satisfy 0 <= min_reviewers <= max_reviewers, max_reviewers >= 1, non-negative max_open_reviews, required_approvals and review_sla_hours, 0 <= business_hours_start < business_hours_end <= 24, a known timezone and reviewer_strategy",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "team not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"settings": settings,
+	})
+}
+
+func (th *teamHandler) DeactivateUsers(c echo.Context) error {
+	var req models.TeamUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	result, err := th.teamService.DeactivateUsers(c.Request().Context(), &req)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "team_name and a non-empty user_ids list are required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "team not found or some users are not its members",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// teamChangeError отвечает на ошибки операций, меняющих состав и имя команды
+func teamChangeError(c echo.Context, err error) error {
+	errCode := err.Error()
+	status := http.StatusConflict
+	var msg string
+	switch errCode {
+	case "INVALID_INPUT":
+		status = http.StatusBadRequest
+		msg = "please check your input"
+	case "NOT_FOUND":
+		status = http.StatusNotFound
+		msg = "team or user not found"
+	case "TEAM_EXISTS":
+		msg = "team with new_team_name already exists"
+	case "TEAM_HAS_OPEN_PRS":
+		msg = "team members have DRAFT or OPEN pull requests, merge, close or move them first"
+	case "HIERARCHY_CYCLE":
+		msg = "parent_team is the team itself or one of its descendants"
+	default:
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(status, echo.Map{
+		"error": map[string]string{
+			"code":    errCode,
+			"message": msg,
+		},
+	})
+}
+
+func (th *teamHandler) AddMembers(c echo.Context) error {
+	var req models.TeamMembersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	team, err := th.teamService.AddMembers(c.Request().Context(), &req)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team": team,
+	})
+}
+
+func (th *teamHandler) RemoveMembers(c echo.Context) error {
+	var req models.TeamUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	result, err := th.teamService.RemoveMembers(c.Request().Context(), &req)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (th *teamHandler) MoveUser(c echo.Context) error {
+	var req models.MoveUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	user, report, err := th.teamService.MoveUser(c.Request().Context(), &req)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"user":         user,
+		"reassigned":   report.Reassigned,
+		"no_candidate": report.NoCandidate,
+	})
+}
+
+func (th *teamHandler) RenameTeam(c echo.Context) error {
+	var req models.RenameTeamRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	team, err := th.teamService.RenameTeam(c.Request().Context(), &req)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team": team,
+	})
+}
+
+func (th *teamHandler) SetParentTeam(c echo.Context) error {
+	var req models.SetParentTeamRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	team, err := th.teamService.SetParentTeam(c.Request().Context(), &req)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"team": team,
+	})
+}
+
+func (th *teamHandler) DeleteTeam(c echo.Context) error {
+	var req struct {
+		TeamName string `json:"team_name"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	result, err := th.teamService.DeleteTeam(c.Request().Context(), req.TeamName)
+	if err != nil {
+		return teamChangeError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (th *teamHandler) SyncTeam(c echo.Context) error {
+	var req models.TeamSyncRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
+
+	diff, err := th.teamService.SyncTeam(c.Request().Context(), &req, dryRun)
+	if err != nil {
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "team_name and a non-empty members list with unique user_id and username are required",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, diff)
+}
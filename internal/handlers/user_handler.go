@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/services"
 	"github.com/labstack/echo/v4"
 )
@@ -10,6 +12,15 @@ import (
 type UserHandler interface {
 	SetUserActive(c echo.Context) error
 	GetPRsByReviewer(c echo.Context) error
+	SetUserSkills(c echo.Context) error
+	SetUserMaxOpenReviews(c echo.Context) error
+	AddUnavailability(c echo.Context) error
+	GetUnavailability(c echo.Context) error
+	UpdateUnavailability(c echo.Context) error
+	DeleteUnavailability(c echo.Context) error
+	GetUser(c echo.Context) error
+	ListUsers(c echo.Context) error
+	DeleteUser(c echo.Context) error
 }
 
 type userHandler struct {
@@ -24,8 +35,9 @@ func NewUserHandler(userService services.UserService) UserHandler {
 
 func (uh *userHandler) SetUserActive(c echo.Context) error {
 	var req struct {
-		UserID   string `json:"user_id"`
-		IsActive bool   `json:"is_active"`
+		UserID              string `json:"user_id"`
+		IsActive            bool   `json:"is_active"`
+		ReassignOpenReviews *bool  `json:"reassign_open_reviews,omitempty"`
 	}
 
 	if err := c.Bind(&req); err != nil {
@@ -37,7 +49,7 @@ func (uh *userHandler) SetUserActive(c echo.Context) error {
 		})
 	}
 
-	user, err := uh.userService.SetUserActive(c.Request().Context(), req.UserID, req.IsActive)
+	user, report, err := uh.userService.SetUserActive(c.Request().Context(), req.UserID, req.IsActive, req.ReassignOpenReviews)
 	if err != nil {
 		if err.Error() == "NOT_FOUND" {
 			return c.JSON(http.StatusNotFound, echo.Map{
@@ -55,14 +67,30 @@ func (uh *userHandler) SetUserActive(c echo.Context) error {
 		})
 	}
 
+	if report != nil {
+		return c.JSON(http.StatusOK, echo.Map{
+			"user":         user,
+			"reassigned":   report.Reassigned,
+			"no_candidate": report.NoCandidate,
+		})
+	}
 	return c.JSON(http.StatusOK, echo.Map{"user": user})
 }
 
 func (uh *userHandler) GetPRsByReviewer(c echo.Context) error {
 	userID := c.QueryParam("user_id")
 
-	prs, err := uh.userService.GetPRsByReviewer(c.Request().Context(), userID)
+	// status=OPEN,MERGED оставляет только пулл реквесты в этих статусах
+	prs, err := uh.userService.GetPRsByReviewer(c.Request().Context(), userID, statusesParam(c))
 	if err != nil {
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "status must be a comma separated list of DRAFT, OPEN, MERGED, CLOSED",
+				},
+			})
+		}
 		if err.Error() == "NOT_FOUND" {
 			return c.JSON(http.StatusNotFound, echo.Map{
 				"error": map[string]string{
@@ -84,3 +112,316 @@ func (uh *userHandler) GetPRsByReviewer(c echo.Context) error {
 		"pull_requests": prs,
 	})
 }
+
+func (uh *userHandler) SetUserSkills(c echo.Context) error {
+	var req struct {
+		UserID string   `json:"user_id"`
+		Skills []string `json:"skills"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	user, err := uh.userService.SetUserSkills(c.Request().Context(), req.UserID, req.Skills)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "user_id is required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"user": user})
+}
+
+func (uh *userHandler) SetUserMaxOpenReviews(c echo.Context) error {
+	var req struct {
+		UserID         string `json:"user_id"`
+		MaxOpenReviews int    `json:"max_open_reviews"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	user, err := uh.userService.SetUserMaxOpenReviews(c.Request().Context(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "user_id and non-negative max_open_reviews are required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"user": user})
+}
+
+func unavailabilityError(c echo.Context, err error) error {
+	switch err.Error() {
+	case "INVALID_INPUT":
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "user_id, starts_at and ends_at after starts_at are required",
+			},
+		})
+	case "NOT_FOUND":
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": map[string]string{
+				"code":    "NOT_FOUND",
+				"message": "user or unavailability period not found",
+			},
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": map[string]string{
+			"code":    "INTERNAL_ERROR",
+			"message": "internal server error",
+		},
+	})
+}
+
+func (uh *userHandler) AddUnavailability(c echo.Context) error {
+	var req models.Unavailability
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	period, err := uh.userService.AddUnavailability(c.Request().Context(), &req)
+	if err != nil {
+		return unavailabilityError(c, err)
+	}
+	return c.JSON(http.StatusCreated, echo.Map{"unavailability": period})
+}
+
+func (uh *userHandler) GetUnavailability(c echo.Context) error {
+	userID := c.QueryParam("user_id")
+	includeExpired, _ := strconv.ParseBool(c.QueryParam("include_expired"))
+
+	periods, err := uh.userService.GetUnavailability(c.Request().Context(), userID, includeExpired)
+	if err != nil {
+		return unavailabilityError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"user_id":        userID,
+		"unavailability": periods,
+	})
+}
+
+func (uh *userHandler) UpdateUnavailability(c echo.Context) error {
+	var req models.Unavailability
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	period, err := uh.userService.UpdateUnavailability(c.Request().Context(), &req)
+	if err != nil {
+		return unavailabilityError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"unavailability": period})
+}
+
+func (uh *userHandler) DeleteUnavailability(c echo.Context) error {
+	var req struct {
+		ID     int64  `json:"id"`
+		UserID string `json:"user_id"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	err := uh.userService.DeleteUnavailability(c.Request().Context(), req.ID, req.UserID)
+	if err != nil {
+		return unavailabilityError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"id":      req.ID,
+		"user_id": req.UserID,
+	})
+}
+
+func (uh *userHandler) GetUser(c echo.Context) error {
+	user, err := uh.userService.GetUser(c.Request().Context(), c.QueryParam("user_id"))
+	if err != nil {
+		if err.Error() == "INVALID_INPUT" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "user_id is required",
+				},
+			})
+		}
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": map[string]string{
+				"code":    "NOT_FOUND",
+				"message": "user not found",
+			},
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"user": user,
+	})
+}
+
+func (uh *userHandler) ListUsers(c echo.Context) error {
+	invalidInput := func(msg string) error {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": msg,
+			},
+		})
+	}
+
+	filter := models.UserListFilter{
+		TeamName:       c.QueryParam("team_name"),
+		UsernamePrefix: c.QueryParam("username_prefix"),
+	}
+
+	var err error
+	if isActive := c.QueryParam("is_active"); isActive != "" {
+		value, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return invalidInput("is_active must be true or false")
+		}
+		filter.IsActive = &value
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return invalidInput("limit must be a number")
+		}
+	}
+
+	page, err := uh.userService.ListUsers(c.Request().Context(), filter, c.QueryParam("cursor"))
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return invalidInput("limit must not be negative")
+		case "INVALID_CURSOR":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_CURSOR",
+					"message": "cursor is malformed",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": "internal server error",
+		})
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (uh *userHandler) DeleteUser(c echo.Context) error {
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": map[string]string{
+				"code":    "INVALID_INPUT",
+				"message": "please check your input",
+			},
+		})
+	}
+
+	user, report, err := uh.userService.DeleteUser(c.Request().Context(), req.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "INVALID_INPUT":
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": map[string]string{
+					"code":    "INVALID_INPUT",
+					"message": "user_id is required",
+				},
+			})
+		case "NOT_FOUND":
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": map[string]string{
+					"code":    "NOT_FOUND",
+					"message": "user not found",
+				},
+			})
+		case "USER_HAS_OPEN_PRS":
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error": map[string]string{
+					"code":    "USER_HAS_OPEN_PRS",
+					"message": "user is the author of draft or open PRs, close or merge them first",
+				},
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": map[string]string{
+				"code":    "INTERNAL_ERROR",
+				"message": "internal server error",
+			},
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"user":         user,
+		"reassigned":   report.Reassigned,
+		"no_candidate": report.NoCandidate,
+	})
+}
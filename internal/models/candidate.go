@@ -0,0 +1,29 @@
+package models
+
+type ReviewCandidate struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	TeamName    string `json:"team_name"`
+	OpenReviews int    `json:"open_reviews"`
+	// действующий лимит OPEN ревью, 0 - без лимита. Заполняется в GetTeamWorkload и GetReviewCandidates
+	MaxOpenReviews int `json:"-"`
+	// заполняется только в GetTeamWorkload
+	Skills []string `json:"-"`
+}
+
+// CandidateFilter - кандидаты берутся из участников команды TeamName и пулов Pools
+type CandidateFilter struct {
+	TeamName       string
+	Pools          []string
+	ExcludeIDs     []string
+	Skills         []string
+	Limit          int
+	IgnoreCapacity bool
+}
+
+// ReviewerAvailability - может ли пользователь взять ревью прямо сейчас: он не в периоде
+// отсутствия и не достиг лимита OPEN ревью, личного или команды
+type ReviewerAvailability struct {
+	Unavailable bool
+	AtCapacity  bool
+}
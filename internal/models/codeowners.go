@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type CodeOwnersScope string
+
+const (
+	CodeOwnersScopeTeam       CodeOwnersScope = "TEAM"
+	CodeOwnersScopeRepository CodeOwnersScope = "REPOSITORY"
+)
+
+// CodeOwnersFile - загруженный CODEOWNERS команды или репозитория.
+// Required означает, что ревью владельца кода обязательно
+type CodeOwnersFile struct {
+	Scope     CodeOwnersScope `json:"scope"`
+	Name      string          `json:"name"`
+	Content   string          `json:"content"`
+	Required  bool            `json:"required"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
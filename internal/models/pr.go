@@ -7,18 +7,119 @@ import (
 type Status string
 
 const (
+	StatusDraft  Status = "DRAFT"
 	StatusOpen   Status = "OPEN"
 	StatusMerged Status = "MERGED"
+	StatusClosed Status = "CLOSED"
 )
 
+// переходы жизненного цикла пулл реквеста, MERGED - конечный статус
+var statusTransitions = map[Status][]Status{
+	StatusDraft:  {StatusOpen, StatusClosed},
+	StatusOpen:   {StatusMerged, StatusClosed},
+	StatusClosed: {StatusOpen},
+}
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusDraft, StatusOpen, StatusMerged, StatusClosed:
+		return true
+	}
+	return false
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type AssignmentSource string
+
+const (
+	SourceTeam      AssignmentSource = "TEAM"
+	SourceCodeOwner AssignmentSource = "CODEOWNER"
+	SourceManual    AssignmentSource = "MANUAL"
+	// ревьюер из родительской или соседней команды: в команде не нашлось кандидатов
+	SourceEscalation AssignmentSource = "ESCALATION"
+	// ревьюер из резервного пула команды
+	SourcePool AssignmentSource = "POOL"
+)
+
+// ReassignReason - причина замены ревьюера, сохраняется в истории переназначений
+type ReassignReason string
+
+const (
+	ReasonManual      ReassignReason = "MANUAL"
+	ReasonSLAExpired  ReassignReason = "SLA_EXPIRED"
+	ReasonDeactivated ReassignReason = "DEACTIVATED"
+	ReasonReorg       ReassignReason = "REORG"
+	ReasonDeleted     ReassignReason = "DELETED"
+)
+
+type ReviewerAssignment struct {
+	ReviewerID string           `json:"user_id"`
+	Source     AssignmentSource `json:"source"`
+}
+
 type PullRequest struct {
-	ID                string     `json:"pull_request_id"`
-	Name              string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            Status     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers,omitempty"`
-	CreatedAt         *time.Time `json:"created_at,omitempty"`
-	MergedAt          *time.Time `json:"merged_at,omitempty"`
+	ID                        string               `json:"pull_request_id"`
+	Name                      string               `json:"pull_request_name"`
+	AuthorID                  string               `json:"author_id"`
+	CoAuthors                 []string             `json:"co_authors,omitempty"`
+	Status                    Status               `json:"status"`
+	Labels                    []string             `json:"labels,omitempty"`
+	AssignedReviewers         []string             `json:"assigned_reviewers,omitempty"`
+	RequiredReviewers         int                  `json:"required_reviewers,omitempty"` // сколько ревьюеров должно быть назначено
+	Assignments               []ReviewerAssignment `json:"assignments,omitempty"`
+	CodeOwnerApprovalRequired bool                 `json:"codeowner_approval_required,omitempty"`
+	ForceMerged               bool                 `json:"force_merged,omitempty"`
+	Repository                string               `json:"repository,omitempty"`
+	ChangedFiles              []string             `json:"changed_files,omitempty"`
+	TargetTeam                string               `json:"target_team,omitempty"`        // команда, в чью кодовую базу вносится вклад
+	AuthorTeamReview          bool                 `json:"author_team_review,omitempty"` // дополнительный ревьюер из команды автора
+	CreatedAt                 *time.Time           `json:"created_at,omitempty"`
+	MergedAt                  *time.Time           `json:"merged_at,omitempty"`
+	ClosedAt                  *time.Time           `json:"closed_at,omitempty"`
+}
+
+// SourceOf возвращает, каким способом ревьюер был назначен на пулл реквест
+func (pr *PullRequest) SourceOf(reviewerID string) AssignmentSource {
+	for _, assignment := range pr.Assignments {
+		if assignment.ReviewerID == reviewerID {
+			return assignment.Source
+		}
+	}
+	return SourceTeam
+}
+
+// Authors возвращает автора и соавторов пулл реквеста. Никто из них не может быть ревьюером
+func (pr *PullRequest) Authors() []string {
+	return append([]string{pr.AuthorID}, pr.CoAuthors...)
+}
+
+// ReviewTeam возвращает команду, из которой подбираются ревьюеры: целевую команду
+// межкомандного пулл реквеста или команду автора
+func (pr *PullRequest) ReviewTeam(authorTeam string) string {
+	if pr.TargetTeam != "" {
+		return pr.TargetTeam
+	}
+	return authorTeam
+}
+
+// ReviewerAssignments возвращает назначенных ревьюеров вместе со способом назначения
+func (pr *PullRequest) ReviewerAssignments() []ReviewerAssignment {
+	assignments := make([]ReviewerAssignment, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		assignments = append(assignments, ReviewerAssignment{
+			ReviewerID: reviewerID,
+			Source:     pr.SourceOf(reviewerID),
+		})
+	}
+	return assignments
 }
 
 type PullRequestShort struct {
@@ -27,3 +128,93 @@ type PullRequestShort struct {
 	AuthorID string `json:"author_id"`
 	Status   Status `json:"status"`
 }
+
+// UnderstaffedPR - OPEN пулл реквест, которому назначено меньше ревьюеров, чем требуется
+type UnderstaffedPR struct {
+	ID                string `json:"pull_request_id"`
+	Name              string `json:"pull_request_name"`
+	AuthorID          string `json:"author_id"`
+	ReviewTeam        string `json:"review_team"`
+	RequiredReviewers int    `json:"required_reviewers"`
+	AssignedCount     int    `json:"assigned_count"`
+}
+
+// ReviewerBackfill - ревьюеры, добавленные пулл реквесту, которому их не хватало
+type ReviewerBackfill struct {
+	PRID      string               `json:"pull_request_id"`
+	Reviewers []ReviewerAssignment `json:"reviewers"`
+}
+
+type MergePRRequest struct {
+	ID    string `json:"pull_request_id"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// AutoReviewer вместо user_id в addReviewer означает выбор ревьюера стратегией команды
+const AutoReviewer = "auto"
+
+type ReviewerChangeRequest struct {
+	PRID   string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+}
+
+type CreatePRRequest struct {
+	ID               string   `json:"pull_request_id"`
+	Name             string   `json:"pull_request_name"`
+	AuthorID         string   `json:"author_id"`
+	CoAuthors        []string `json:"co_authors,omitempty"`
+	Repository       string   `json:"repository,omitempty"`
+	ChangedFiles     []string `json:"changed_files,omitempty"`
+	Labels           []string `json:"labels,omitempty"`
+	Draft            bool     `json:"draft,omitempty"`
+	TargetTeam       string   `json:"target_team,omitempty"`        // ревьюеры подбираются в этой команде вместо команды автора
+	AuthorTeamReview bool     `json:"author_team_review,omitempty"` // плюс один ревьюер из команды автора
+}
+
+type Reassignment struct {
+	PRID          string         `json:"pull_request_id"`
+	OldReviewerID string         `json:"old_reviewer_id"`
+	NewReviewerID string         `json:"new_reviewer_id"`
+	Reason        ReassignReason `json:"reason"`
+	Escalated     bool           `json:"escalated,omitempty"` // замена найдена в родительской или соседней команде
+	FromPool      bool           `json:"from_pool,omitempty"` // замена взята из резервного пула команды
+}
+
+// Source возвращает способ назначения нового ревьюера
+func (r *Reassignment) Source() AssignmentSource {
+	switch {
+	case r.Escalated:
+		return SourceEscalation
+	case r.FromPool:
+		return SourcePool
+	}
+	return SourceTeam
+}
+
+// ReassignmentReport - результат переназначения OPEN ревью пользователя
+type ReassignmentReport struct {
+	Reassigned  []Reassignment `json:"reassigned"`
+	NoCandidate []string       `json:"no_candidate"`
+	// позиции ротации команд после плана, сохраняются вместе с переназначениями
+	Rotation map[string]string `json:"-"`
+}
+
+// PendingReview - назначение ревьюера, по которому он еще не принял решение
+type PendingReview struct {
+	PRID       string
+	ReviewerID string
+	TeamName   string // ревьюящая команда, по ее SLA считается просрочка
+	AssignedAt time.Time
+}
+
+// OpenAssignment - назначение ревьюера на OPEN пулл реквест вместе с данными,
+// нужными для подбора замены без дополнительных запросов
+type OpenAssignment struct {
+	PRID       string
+	ReviewerID string
+	AuthorID   string
+	CoAuthors  []string
+	TeamName   string // ревьюящая команда: целевая или основная команда автора
+	Labels     []string
+	Reviewers  []string
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+type PRSortField string
+
+const (
+	SortByCreatedAt PRSortField = "created_at"
+	SortByMergedAt  PRSortField = "merged_at"
+	SortByName      PRSortField = "name"
+)
+
+func (f PRSortField) IsValid() bool {
+	switch f {
+	case SortByCreatedAt, SortByMergedAt, SortByName:
+		return true
+	}
+	return false
+}
+
+const (
+	DefaultPRPageSize = 50
+	MaxPRPageSize     = 200
+)
+
+// PRListFilter - фильтры и сортировка выборки пулл реквестов.
+// Пустые поля не ограничивают выборку, границы дат включаются
+type PRListFilter struct {
+	Statuses     []Status
+	AuthorID     string
+	ReviewerID   string
+	TeamName     string
+	NameContains string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	MergedFrom   *time.Time
+	MergedTo     *time.Time
+	SortBy       PRSortField
+	Desc         bool
+	Limit        int
+	After        *PRCursor
+}
+
+// PRCursor - ключ сортировки последнего пулл реквеста на странице.
+// Клиенту отдается в закодированном виде, сортировка сохраняется,
+// чтобы курсор нельзя было применить к выборке с другим порядком
+type PRCursor struct {
+	SortBy PRSortField `json:"s"`
+	Desc   bool        `json:"d,omitempty"`
+	Time   *time.Time  `json:"t,omitempty"`
+	Name   string      `json:"n,omitempty"`
+	ID     string      `json:"id"`
+}
+
+type PRPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type ReviewDecision string
+
+const (
+	DecisionApproved         ReviewDecision = "APPROVED"
+	DecisionChangesRequested ReviewDecision = "CHANGES_REQUESTED"
+	DecisionCommented        ReviewDecision = "COMMENTED"
+)
+
+func (d ReviewDecision) IsValid() bool {
+	switch d {
+	case DecisionApproved, DecisionChangesRequested, DecisionCommented:
+		return true
+	}
+	return false
+}
+
+type Review struct {
+	ID          int64          `json:"id"`
+	PRID        string         `json:"pull_request_id"`
+	ReviewerID  string         `json:"reviewer_id"`
+	Decision    ReviewDecision `json:"decision"`
+	Comment     string         `json:"comment,omitempty"`
+	SubmittedAt *time.Time     `json:"submitted_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// PoolMode - как команда использует пул ревьюеров
+type PoolMode string
+
+const (
+	// участники пула подбираются стратегией команды наравне с ее участниками
+	PoolSupplementary PoolMode = "SUPPLEMENTARY"
+	// к пулу обращаются, только если в команде не хватило кандидатов
+	PoolFallback PoolMode = "FALLBACK"
+)
+
+func (m PoolMode) IsValid() bool {
+	switch m {
+	case PoolSupplementary, PoolFallback:
+		return true
+	}
+	return false
+}
+
+type ReviewerPool struct {
+	Name    string `json:"pool_name"`
+	Members []User `json:"members"`
+}
+
+// SavePoolRequest задает состав пула целиком, пул создается при первом сохранении
+type SavePoolRequest struct {
+	Name    string   `json:"pool_name"`
+	UserIDs []string `json:"user_ids"`
+}
+
+// TeamPool - подключение пула к команде
+type TeamPool struct {
+	TeamName string   `json:"team_name"`
+	PoolName string   `json:"pool_name"`
+	Mode     PoolMode `json:"mode"`
+}
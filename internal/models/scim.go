@@ -0,0 +1,85 @@
+package models
+
+import "encoding/json"
+
+const (
+	SCIMUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMPatchSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+type SCIMMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+// SCIMRef - ссылка на связанный ресурс: команда пользователя или участник группы
+type SCIMRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMUser - пользователь в представлении SCIM. id совпадает с users.id, при создании
+// берется из externalId, а если его нет - из userName
+type SCIMUser struct {
+	Schemas    []string  `json:"schemas"`
+	ID         string    `json:"id"`
+	ExternalID string    `json:"externalId,omitempty"`
+	UserName   string    `json:"userName"`
+	Active     *bool     `json:"active,omitempty"`
+	Groups     []SCIMRef `json:"groups,omitempty"`
+	Meta       *SCIMMeta `json:"meta,omitempty"`
+}
+
+// SCIMGroup - команда в представлении SCIM, id и displayName совпадают с именем команды
+type SCIMGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id"`
+	DisplayName string    `json:"displayName"`
+	Members     []SCIMRef `json:"members"`
+	Meta        *SCIMMeta `json:"meta,omitempty"`
+}
+
+// SCIMCondition - условие фильтра SCIM: имя атрибута в нижнем регистре, оператор eq, ne, co, sw, ew
+// или pr и значение для сравнения без учета регистра
+type SCIMCondition struct {
+	Attr  string
+	Op    string
+	Value string
+}
+
+// SCIMQuery - выборка ресурсов SCIM: условия объединяются через and, страница задается
+// смещением Offset и размером Limit
+type SCIMQuery struct {
+	Conditions []SCIMCondition
+	Offset     int
+	Limit      int
+}
+
+type SCIMListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+type SCIMPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string      `json:"schemas"`
+	Operations []SCIMPatchOp `json:"Operations"`
+}
+
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
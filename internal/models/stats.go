@@ -1,13 +1,24 @@
 package models
 
 type UserStats struct {
-	UserID      string `json:"user_id"`
-	Username    string `json:"username"`
-	ReviewCount int    `json:"review_count"`
-	IsActive    bool   `json:"is_active"`
+	UserID        string `json:"user_id"`
+	Username      string `json:"username"`
+	ReviewCount   int    `json:"review_count"`
+	AuthoredCount int    `json:"authored_count"` // пулл реквесты, где пользователь автор или соавтор
+	IsActive      bool   `json:"is_active"`
+}
+
+// CrossTeamStats - пулл реквесты авторов из других команд в кодовую базу команды
+type CrossTeamStats struct {
+	TargetTeam   string `json:"target_team"`
+	PRCount      int    `json:"pr_count"`
+	MergedCount  int    `json:"merged_count"`
+	Contributors int    `json:"contributors"`
 }
 
 type StatsResponse struct {
-	TotalPRsCreated int         `json:"total_prs_created"`
-	ReviewsByUser   []UserStats `json:"reviews_by_user"`
+	TotalPRsCreated int              `json:"total_prs_created"`
+	ReviewsByUser   []UserStats      `json:"reviews_by_user"`
+	CrossTeamPRs    int              `json:"cross_team_prs"`
+	CrossTeam       []CrossTeamStats `json:"cross_team_contributions"`
 }
@@ -1,12 +1,228 @@
 package models
 
+import "time"
+
+type ReviewerStrategy string
+
+const (
+	StrategyRandom      ReviewerStrategy = "random"
+	StrategyRoundRobin  ReviewerStrategy = "round_robin"
+	StrategyLeastLoaded ReviewerStrategy = "least_loaded"
+)
+
+func (s ReviewerStrategy) IsValid() bool {
+	switch s {
+	case StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
 type Team struct {
-	Name    string       `json:"team_name"`
-	Members []TeamMember `json:"members"`
+	Name             string           `json:"team_name"`
+	ParentTeam       string           `json:"parent_team,omitempty"`
+	ReviewerStrategy ReviewerStrategy `json:"reviewer_strategy,omitempty"`
+	Members          []TeamMember     `json:"members"`
 }
 
 type TeamMember struct {
+	UserID    string   `json:"user_id"`
+	Username  string   `json:"username"`
+	IsActive  bool     `json:"is_active"`
+	Skills    []string `json:"skills,omitempty"`
+	IsPrimary bool     `json:"is_primary"` // false - команда для участника дополнительная
+}
+
+type TeamSettings struct {
+	TeamName             string           `json:"team_name"`
+	MinReviewers         int              `json:"min_reviewers"`
+	MaxReviewers         int              `json:"max_reviewers"`
+	ReviewerStrategy     ReviewerStrategy `json:"reviewer_strategy"`
+	MaxOpenReviews       int              `json:"max_open_reviews"`
+	RequiredApprovals    int              `json:"required_approvals"`
+	ReviewSLAHours       int              `json:"review_sla_hours"` // 0 - без SLA
+	SLABusinessHoursOnly bool             `json:"sla_business_hours_only"`
+	BusinessHoursStart   int              `json:"business_hours_start"`
+	BusinessHoursEnd     int              `json:"business_hours_end"`
+	Timezone             string           `json:"timezone"`
+	ReassignOnDeactivate bool             `json:"reassign_on_deactivate"`
+}
+
+func DefaultTeamSettings(teamName string) *TeamSettings {
+	return &TeamSettings{
+		TeamName:           teamName,
+		MinReviewers:       0,
+		MaxReviewers:       2,
+		ReviewerStrategy:   StrategyRandom,
+		BusinessHoursStart: 9,
+		BusinessHoursEnd:   18,
+		Timezone:           "UTC",
+	}
+}
+
+func (s *TeamSettings) IsValid() bool {
+	return s.MinReviewers >= 0 && s.MaxReviewers >= 1 &&
+		s.MinReviewers <= s.MaxReviewers && s.ReviewerStrategy.IsValid() &&
+		s.MaxOpenReviews >= 0 && s.RequiredApprovals >= 0 && s.ReviewSLAHours >= 0 &&
+		s.BusinessHoursStart >= 0 && s.BusinessHoursEnd <= 24 && s.BusinessHoursStart < s.BusinessHoursEnd &&
+		s.location() != nil
+}
+
+func (s *TeamSettings) location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// Location возвращает часовой пояс команды, при некорректном значении - UTC
+func (s *TeamSettings) Location() *time.Location {
+	if loc := s.location(); loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// TeamSettingsUpdate - частичное обновление настроек, nil поля не меняются
+type TeamSettingsUpdate struct {
+	TeamName             string            `json:"team_name"`
+	MinReviewers         *int              `json:"min_reviewers,omitempty"`
+	MaxReviewers         *int              `json:"max_reviewers,omitempty"`
+	ReviewerStrategy     *ReviewerStrategy `json:"reviewer_strategy,omitempty"`
+	MaxOpenReviews       *int              `json:"max_open_reviews,omitempty"`
+	RequiredApprovals    *int              `json:"required_approvals,omitempty"`
+	ReviewSLAHours       *int              `json:"review_sla_hours,omitempty"`
+	SLABusinessHoursOnly *bool             `json:"sla_business_hours_only,omitempty"`
+	BusinessHoursStart   *int              `json:"business_hours_start,omitempty"`
+	BusinessHoursEnd     *int              `json:"business_hours_end,omitempty"`
+	Timezone             *string           `json:"timezone,omitempty"`
+	ReassignOnDeactivate *bool             `json:"reassign_on_deactivate,omitempty"`
+}
+
+func (u *TeamSettingsUpdate) Apply(s *TeamSettings) {
+	if u.MinReviewers != nil {
+		s.MinReviewers = *u.MinReviewers
+	}
+	if u.MaxReviewers != nil {
+		s.MaxReviewers = *u.MaxReviewers
+	}
+	if u.ReviewerStrategy != nil {
+		s.ReviewerStrategy = *u.ReviewerStrategy
+	}
+	if u.MaxOpenReviews != nil {
+		s.MaxOpenReviews = *u.MaxOpenReviews
+	}
+	if u.RequiredApprovals != nil {
+		s.RequiredApprovals = *u.RequiredApprovals
+	}
+	if u.ReviewSLAHours != nil {
+		s.ReviewSLAHours = *u.ReviewSLAHours
+	}
+	if u.SLABusinessHoursOnly != nil {
+		s.SLABusinessHoursOnly = *u.SLABusinessHoursOnly
+	}
+	if u.BusinessHoursStart != nil {
+		s.BusinessHoursStart = *u.BusinessHoursStart
+	}
+	if u.BusinessHoursEnd != nil {
+		s.BusinessHoursEnd = *u.BusinessHoursEnd
+	}
+	if u.Timezone != nil {
+		s.Timezone = *u.Timezone
+	}
+	if u.ReassignOnDeactivate != nil {
+		s.ReassignOnDeactivate = *u.ReassignOnDeactivate
+	}
+}
+
+// TeamUsersRequest - операция над несколькими участниками команды
+type TeamUsersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type DeactivationResult struct {
+	TeamName    string         `json:"team_name"`
+	Deactivated []User         `json:"deactivated"`
+	Detached    []User         `json:"detached,omitempty"` // покинули дополнительную команду, остались активными
+	Reassigned  []Reassignment `json:"reassigned"`
+	NoCandidate []string       `json:"no_candidate"`
+}
+
+type TeamMembersRequest struct {
+	TeamName string       `json:"team_name"`
+	Members  []TeamMember `json:"members"`
+}
+
+type MoveUserRequest struct {
 	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	TeamName string `json:"team_name"`
+}
+
+// SetParentTeamRequest - пустой parent_team делает команду корневой
+type SetParentTeamRequest struct {
+	TeamName   string `json:"team_name"`
+	ParentTeam string `json:"parent_team"`
+}
+
+type RenameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+// TeamDeletionResult - участники удаленной команды остаются активными и в других своих командах,
+// для кого она была основной, остаются без основной команды. Их OPEN ревью, которые держались
+// на участии в удаленной команде, передаются другим ревьюерам
+type TeamDeletionResult struct {
+	TeamName    string         `json:"team_name"`
+	Detached    []User         `json:"detached"`
+	Reassigned  []Reassignment `json:"reassigned"`
+	NoCandidate []string       `json:"no_candidate"`
+}
+
+// SyncMember - участник в желаемом составе команды. Все перечисленные участники активны,
+// skills = nil оставляет навыки без изменений
+type SyncMember struct {
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Skills   []string `json:"skills,omitempty"`
+}
+
+type TeamSyncRequest struct {
+	TeamName string       `json:"team_name"`
+	Members  []SyncMember `json:"members"`
+}
+
+type UsernameChange struct {
+	UserID      string `json:"user_id"`
+	OldUsername string `json:"old_username"`
+	NewUsername string `json:"new_username"`
+}
+
+// TeamMove - пользователь, переводимый в команду. FromTeam пустой, если он был без команды
+type TeamMove struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team"`
+}
+
+// TeamSyncDiff - изменения, которые синхронизация вносит (или при dry_run внесла бы) в бд
+type TeamSyncDiff struct {
+	TeamName      string           `json:"team_name"`
+	DryRun        bool             `json:"dry_run"`
+	TeamCreated   bool             `json:"team_created"`
+	Created       []User           `json:"created"`
+	Renamed       []UsernameChange `json:"renamed"`
+	MovedIn       []TeamMove       `json:"moved_in"`
+	Reactivated   []string         `json:"reactivated"`
+	SkillsChanged []string         `json:"skills_changed"`
+	Deactivated   []string         `json:"deactivated"`
+	Reassigned    []Reassignment   `json:"reassigned"`
+	NoCandidate   []string         `json:"no_candidate"`
+}
+
+// IsEmpty сообщает, что состав в бд уже совпадает с желаемым
+func (d *TeamSyncDiff) IsEmpty() bool {
+	return !d.TeamCreated && len(d.Created) == 0 && len(d.Renamed) == 0 && len(d.MovedIn) == 0 &&
+		len(d.Reactivated) == 0 && len(d.SkillsChanged) == 0 && len(d.Deactivated) == 0
 }
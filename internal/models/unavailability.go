@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Unavailability - период, когда пользователь не получает ревью, даже если is_active = true
+type Unavailability struct {
+	ID       int64     `json:"id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
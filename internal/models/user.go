@@ -1,8 +1,33 @@
 package models
 
+import "strings"
+
 type User struct {
-	ID       string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	ID             string   `json:"user_id"`
+	Username       string   `json:"username"`
+	TeamName       string   `json:"team_name"`
+	IsActive       bool     `json:"is_active"`
+	Skills         []string `json:"skills,omitempty"`
+	MaxOpenReviews int      `json:"max_open_reviews,omitempty"`
+}
+
+// UserWithTeams - пользователь вместе со всеми командами, в которых он состоит
+type UserWithTeams struct {
+	User
+	Teams []string
+}
+
+// NormalizeTags приводит теги навыков и метки пулл реквестов к нижнему регистру и убирает дубли
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
 }
@@ -0,0 +1,21 @@
+package models
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 200
+)
+
+// UserListFilter - фильтры выборки пользователей. Пустые поля не ограничивают выборку,
+// пользователи упорядочены по id, страница начинается строго после AfterID
+type UserListFilter struct {
+	TeamName       string
+	IsActive       *bool
+	UsernamePrefix string
+	Limit          int
+	AfterID        string
+}
+
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
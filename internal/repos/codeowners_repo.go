@@ -0,0 +1,64 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrCodeOwnersNotFound - для области не загружен CODEOWNERS
+var ErrCodeOwnersNotFound = errors.New("CODEOWNERS не найден")
+
+type CodeOwnersRepo interface {
+	GetCodeOwners(ctx context.Context, scope models.CodeOwnersScope, name string) (*models.CodeOwnersFile, error)
+	UpsertCodeOwners(ctx context.Context, file *models.CodeOwnersFile) error
+}
+
+type codeOwnersRepo struct {
+	db DBInterface
+}
+
+func NewCodeOwnersRepo(db DBInterface) CodeOwnersRepo {
+	return &codeOwnersRepo{
+		db: db,
+	}
+}
+
+func (cor *codeOwnersRepo) GetCodeOwners(ctx context.Context, scope models.CodeOwnersScope, name string) (*models.CodeOwnersFile, error) {
+	var file models.CodeOwnersFile
+
+	query := `
+		SELECT scope, name, content, required, updated_at
+		FROM codeowners
+		WHERE scope = $1 AND name = $2
+	`
+	row := cor.db.QueryRow(ctx, query, scope, name)
+	err := row.Scan(&file.Scope, &file.Name, &file.Content, &file.Required, &file.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrCodeOwnersNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить CODEOWNERS: %v", err)
+	}
+	return &file, nil
+}
+
+func (cor *codeOwnersRepo) UpsertCodeOwners(ctx context.Context, file *models.CodeOwnersFile) error {
+	query := `
+		INSERT INTO codeowners (scope, name, content, required)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, name)
+		DO UPDATE SET
+			content = EXCLUDED.content,
+			required = EXCLUDED.required,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := cor.db.Exec(ctx, query, file.Scope, file.Name, file.Content, file.Required)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении CODEOWNERS: %v", err)
+	}
+	return nil
+}
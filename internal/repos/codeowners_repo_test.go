@@ -0,0 +1,102 @@
+package repos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwnersRepo_GetCodeOwners(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewCodeOwnersRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT scope, name, content, required, updated_at FROM codeowners WHERE scope = \$1 AND name = \$2`
+
+	t.Run("успешное получение CODEOWNERS", func(t *testing.T) {
+		updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		expected := &models.CodeOwnersFile{
+			Scope:     models.CodeOwnersScopeTeam,
+			Name:      "backend",
+			Content:   "*.go @gopher",
+			Required:  true,
+			UpdatedAt: &updatedAt,
+		}
+
+		mock.ExpectQuery(query).
+			WithArgs(models.CodeOwnersScopeTeam, "backend").
+			WillReturnRows(pgxmock.NewRows([]string{"scope", "name", "content", "required", "updated_at"}).
+				AddRow(expected.Scope, expected.Name, expected.Content, expected.Required, expected.UpdatedAt))
+
+		file, err := repo.GetCodeOwners(ctx, models.CodeOwnersScopeTeam, "backend")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, file)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CODEOWNERS не найден", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(models.CodeOwnersScopeRepository, "avito/app").
+			WillReturnError(pgx.ErrNoRows)
+
+		file, err := repo.GetCodeOwners(ctx, models.CodeOwnersScopeRepository, "avito/app")
+
+		assert.Error(t, err)
+		assert.Equal(t, "CODEOWNERS не найден", err.Error())
+		assert.ErrorIs(t, err, repos.ErrCodeOwnersNotFound)
+		assert.Nil(t, file)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCodeOwnersRepo_UpsertCodeOwners(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewCodeOwnersRepo(db)
+
+	ctx := context.Background()
+	file := &models.CodeOwnersFile{
+		Scope:   models.CodeOwnersScopeRepository,
+		Name:    "avito/app",
+		Content: "/docs/ @writer",
+	}
+	query := `INSERT INTO codeowners \(scope, name, content, required\) VALUES \(\$1, \$2, \$3, \$4\) ON CONFLICT \(scope, name\) DO UPDATE`
+
+	t.Run("успешное сохранение CODEOWNERS", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(file.Scope, file.Name, file.Content, file.Required).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.UpsertCodeOwners(ctx, file)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(file.Scope, file.Name, file.Content, file.Required).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		err := repo.UpsertCodeOwners(ctx, file)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при сохранении CODEOWNERS")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
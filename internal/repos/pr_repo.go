@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -12,12 +13,21 @@ import (
 type PRRepo interface {
 	CreatePR(ctx context.Context, pr *models.PullRequest) error
 	GetPRByID(ctx context.Context, prID string) (*models.PullRequest, error)
-	GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	GetPRsByReviewer(ctx context.Context, userID string, statuses []models.Status) ([]models.PullRequestShort, error)
+	ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error)
 	UpdatePRStatus(ctx context.Context, prID string, status models.Status) (*models.PullRequest, error)
-	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error
+	MergePR(ctx context.Context, prID string, force bool) (*models.PullRequest, error)
+	TransitionPR(ctx context.Context, prID string, from, to models.Status, reviewers []models.ReviewerAssignment, requiredReviewers int) (*models.PullRequest, error)
+	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string, source models.AssignmentSource, reason models.ReassignReason) error
+	GetPendingReviews(ctx context.Context) ([]models.PendingReview, error)
+	GetOpenAssignments(ctx context.Context, reviewerIDs []string) ([]models.OpenAssignment, error)
+	AddReviewer(ctx context.Context, prID, reviewerID string, source models.AssignmentSource) error
+	RemoveReviewer(ctx context.Context, prID, reviewerID string) error
+	GetUnderstaffedPRs(ctx context.Context, teamNames []string) ([]models.UnderstaffedPR, error)
 	IsPRMerged(ctx context.Context, prID string) (*bool, error)
 	GetTotalPRCount(ctx context.Context) (int, error)
 	GetReviewCountByUser(ctx context.Context) ([]models.UserStats, error)
+	GetCrossTeamStats(ctx context.Context) ([]models.CrossTeamStats, error)
 }
 
 type prRepo struct {
@@ -33,28 +43,32 @@ func NewPRRepo(db DBInterface) PRRepo {
 func (prr *prRepo) CreatePR(ctx context.Context, pr *models.PullRequest) error {
 	txFunc := func(tx pgx.Tx) error {
 		query := `
-			INSERT INTO pull_requests (id, name, author_id)
-			VALUES ($1, $2, $3)
+			INSERT INTO pull_requests (id, name, author_id, status, codeowner_approval_required, labels, repository, changed_files,
+				target_team, author_team_review, required_reviewers)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11)
 		`
 
-		_, err := tx.Exec(ctx, query, pr.ID, pr.Name, pr.AuthorID)
+		changedFiles := pr.ChangedFiles
+		if changedFiles == nil {
+			changedFiles = []string{}
+		}
+		_, err := tx.Exec(ctx, query, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CodeOwnerApprovalRequired,
+			models.NormalizeTags(pr.Labels), pr.Repository, changedFiles, pr.TargetTeam, pr.AuthorTeamReview, pr.RequiredReviewers)
 		if err != nil {
 			return fmt.Errorf("ошибка при создании пулл реквеста: %v", err)
 		}
 
-		if len(pr.AssignedReviewers) > 0 {
-			query := `
-				INSERT INTO pr_reviewers (pr_id, reviewer_id)
-				VALUES ($1, $2)
-			`
-			for _, reviewerID := range pr.AssignedReviewers {
-				_, err := tx.Exec(ctx, query, pr.ID, reviewerID)
-				if err != nil {
-					return fmt.Errorf("ошибка при добавлении ревьюера: %v", err)
-				}
+		if len(pr.CoAuthors) > 0 {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO pr_co_authors (pr_id, user_id)
+				SELECT $1, unnest($2::text[])
+			`, pr.ID, pr.CoAuthors)
+			if err != nil {
+				return fmt.Errorf("ошибка при добавлении соавторов: %v", err)
 			}
 		}
-		return nil
+
+		return insertReviewers(ctx, tx, pr.ID, pr.ReviewerAssignments())
 	}
 	err := prr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
 	if err != nil {
@@ -64,17 +78,35 @@ func (prr *prRepo) CreatePR(ctx context.Context, pr *models.PullRequest) error {
 	return nil
 }
 
+func insertReviewers(ctx context.Context, tx pgx.Tx, prID string, assignments []models.ReviewerAssignment) error {
+	query := `
+		INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_via)
+		VALUES ($1, $2, $3)
+	`
+	for _, assignment := range assignments {
+		_, err := tx.Exec(ctx, query, prID, assignment.ReviewerID, assignment.Source)
+		if err != nil {
+			return fmt.Errorf("ошибка при добавлении ревьюера: %v", err)
+		}
+	}
+	return nil
+}
+
 func (prr *prRepo) GetPRByID(ctx context.Context, prID string) (*models.PullRequest, error) {
 	var pr models.PullRequest
 
 	query := `
-		SELECT id, name, author_id, status
+		SELECT id, name, COALESCE(author_id, ''), status, codeowner_approval_required, labels, force_merged,
+			repository, changed_files, created_at, merged_at, closed_at, COALESCE(target_team, ''), author_team_review, required_reviewers,
+			ARRAY(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id) AS co_authors
 		FROM pull_requests
 		WHERE id = $1
 	`
 
 	row := prr.db.QueryRow(ctx, query, prID)
-	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status)
+	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CodeOwnerApprovalRequired, &pr.Labels, &pr.ForceMerged,
+		&pr.Repository, &pr.ChangedFiles, &pr.CreatedAt, &pr.MergedAt, &pr.ClosedAt, &pr.TargetTeam, &pr.AuthorTeamReview,
+		&pr.RequiredReviewers, &pr.CoAuthors)
 	if err == pgx.ErrNoRows {
 		return nil, errors.New("пулл реквест не найден")
 	}
@@ -83,7 +115,7 @@ func (prr *prRepo) GetPRByID(ctx context.Context, prID string) (*models.PullRequ
 	}
 
 	reviewersQuery := `
-		SELECT reviewer_id
+		SELECT reviewer_id, assigned_via
 		FROM pr_reviewers
 		WHERE pr_id = $1
 	`
@@ -95,31 +127,35 @@ func (prr *prRepo) GetPRByID(ctx context.Context, prID string) (*models.PullRequ
 	defer reviewersRows.Close()
 
 	var reviewers []string
+	var assignments []models.ReviewerAssignment
 	for reviewersRows.Next() {
-		var reviewerID string
-		err := reviewersRows.Scan(&reviewerID)
+		var assignment models.ReviewerAssignment
+		err := reviewersRows.Scan(&assignment.ReviewerID, &assignment.Source)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании ревьюера: %v", err)
 		}
-		reviewers = append(reviewers, reviewerID)
+		reviewers = append(reviewers, assignment.ReviewerID)
+		assignments = append(assignments, assignment)
 	}
 
 	pr.AssignedReviewers = reviewers
+	pr.Assignments = assignments
 
 	return &pr, nil
 }
 
-func (prr *prRepo) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+// GetPRsByReviewer возвращает пулл реквесты ревьюера, пустой statuses - в любом статусе
+func (prr *prRepo) GetPRsByReviewer(ctx context.Context, userID string, statuses []models.Status) ([]models.PullRequestShort, error) {
 	var prs []models.PullRequestShort
 
 	query := `
-		SELECT p.id, p.name, p.author_id, p.status
+		SELECT p.id, p.name, COALESCE(p.author_id, ''), p.status
 		FROM pull_requests p
 		JOIN pr_reviewers r ON p.id = r.pr_id
-		WHERE r.reviewer_id = $1
+		WHERE r.reviewer_id = $1 AND (cardinality($2::text[]) = 0 OR p.status = ANY($2))
 	`
 
-	rows, err := prr.db.Query(ctx, query, userID)
+	rows, err := prr.db.Query(ctx, query, userID, statusStrings(statuses))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении пулл реквестов: %v", err)
 	}
@@ -140,6 +176,137 @@ func (prr *prRepo) GetPRsByReviewer(ctx context.Context, userID string) ([]model
 	return prs, nil
 }
 
+func statusStrings(statuses []models.Status) []string {
+	result := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, string(status))
+	}
+	return result
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListPRs возвращает до filter.Limit пулл реквестов с ревьюерами. Пагинация по ключу
+// сортировки и id: следующая страница начинается строго после filter.After.
+// При сортировке по merged_at в выборку попадают только смерженные пулл реквесты
+func (prr *prRepo) ListPRs(ctx context.Context, filter models.PRListFilter) ([]models.PullRequest, error) {
+	query := `
+		SELECT p.id, p.name, COALESCE(p.author_id, ''), p.status, p.codeowner_approval_required, p.labels, p.force_merged,
+			p.repository, p.changed_files, p.created_at, p.merged_at, p.closed_at, COALESCE(p.target_team, ''), p.author_team_review,
+			p.required_reviewers, ARRAY(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = p.id ORDER BY c.user_id) AS co_authors
+		FROM pull_requests p
+		WHERE (cardinality($1::text[]) = 0 OR p.status = ANY($1))
+			AND ($2::text = '' OR p.author_id = $2)
+			AND ($3::text = '' OR EXISTS (
+				SELECT 1 FROM pr_reviewers r WHERE r.pr_id = p.id AND r.reviewer_id = $3
+			))
+			AND ($4::text = '' OR EXISTS (
+				SELECT 1 FROM users a WHERE a.id = p.author_id AND a.team_name = $4
+			))
+			AND ($5::text = '' OR p.name ILIKE '%%' || $5 || '%%')
+			AND ($6::timestamp IS NULL OR p.created_at >= $6)
+			AND ($7::timestamp IS NULL OR p.created_at <= $7)
+			AND ($8::timestamp IS NULL OR p.merged_at >= $8)
+			AND ($9::timestamp IS NULL OR p.merged_at <= $9)
+			%s
+		ORDER BY %s
+		LIMIT $10
+	`
+
+	args := []any{
+		statusStrings(filter.Statuses), filter.AuthorID, filter.ReviewerID, filter.TeamName,
+		likeEscaper.Replace(filter.NameContains),
+		filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo, filter.Limit,
+	}
+
+	// столбец и направление берутся из закрытого списка, значения курсора передаются параметрами
+	column := "p.created_at"
+	switch filter.SortBy {
+	case models.SortByMergedAt:
+		column = "p.merged_at"
+	case models.SortByName:
+		column = "p.name"
+	}
+	direction, cmp := "ASC", ">"
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	var keyset string
+	if filter.SortBy == models.SortByMergedAt {
+		keyset = "AND p.merged_at IS NOT NULL"
+	}
+	if filter.After != nil {
+		var value any = filter.After.Time
+		if filter.SortBy == models.SortByName {
+			value = filter.After.Name
+		}
+		keyset += fmt.Sprintf(" AND (%s, p.id) %s ($11, $12)", column, cmp)
+		args = append(args, value, filter.After.ID)
+	}
+
+	query = fmt.Sprintf(query, keyset, fmt.Sprintf("%s %s, p.id %s", column, direction, direction))
+
+	rows, err := prr.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении пулл реквестов: %v", err)
+	}
+	defer rows.Close()
+
+	var prs []models.PullRequest
+	var ids []string
+	for rows.Next() {
+		var pr models.PullRequest
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CodeOwnerApprovalRequired, &pr.Labels, &pr.ForceMerged,
+			&pr.Repository, &pr.ChangedFiles, &pr.CreatedAt, &pr.MergedAt, &pr.ClosedAt, &pr.TargetTeam, &pr.AuthorTeamReview,
+			&pr.RequiredReviewers, &pr.CoAuthors)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		prs = append(prs, pr)
+		ids = append(ids, pr.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	if len(prs) == 0 {
+		return prs, nil
+	}
+
+	reviewersQuery := `
+		SELECT pr_id, reviewer_id, assigned_via
+		FROM pr_reviewers
+		WHERE pr_id = ANY($1)
+		ORDER BY pr_id, reviewer_id
+	`
+
+	reviewersRows, err := prr.db.Query(ctx, reviewersQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ревьюеров: %v", err)
+	}
+	defer reviewersRows.Close()
+
+	index := make(map[string]int, len(prs))
+	for i, pr := range prs {
+		index[pr.ID] = i
+	}
+	for reviewersRows.Next() {
+		var prID string
+		var assignment models.ReviewerAssignment
+		err := reviewersRows.Scan(&prID, &assignment.ReviewerID, &assignment.Source)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ревьюера: %v", err)
+		}
+		pr := &prs[index[prID]]
+		pr.AssignedReviewers = append(pr.AssignedReviewers, assignment.ReviewerID)
+		pr.Assignments = append(pr.Assignments, assignment)
+	}
+	if err := reviewersRows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при сканировании ревьюеров: %v", err)
+	}
+	return prs, nil
+}
+
 func (prr *prRepo) UpdatePRStatus(ctx context.Context, prID string, status models.Status) (*models.PullRequest, error) {
 	query := `
 		UPDATE pull_requests
@@ -154,7 +321,51 @@ func (prr *prRepo) UpdatePRStatus(ctx context.Context, prID string, status model
 	return prr.GetPRByID(ctx, prID)
 }
 
-func (prr *prRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error {
+// MergePR переводит пулл реквест в MERGED и отмечает, был ли мердж выполнен в обход аппрувов
+func (prr *prRepo) MergePR(ctx context.Context, prID string, force bool) (*models.PullRequest, error) {
+	query := `
+		UPDATE pull_requests
+		SET status = 'MERGED', merged_at = CURRENT_TIMESTAMP, force_merged = $1
+		WHERE id = $2
+	`
+	_, err := prr.db.Exec(ctx, query, force, prID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при мердже пулл реквеста: %v", err)
+	}
+	return prr.GetPRByID(ctx, prID)
+}
+
+// TransitionPR переводит пулл реквест из статуса from в to и в той же транзакции добавляет
+// ревьюеров. Если статус уже изменился, возвращает ошибку
+func (prr *prRepo) TransitionPR(ctx context.Context, prID string, from, to models.Status, reviewers []models.ReviewerAssignment, requiredReviewers int) (*models.PullRequest, error) {
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE pull_requests
+			SET status = $1, closed_at = CASE WHEN $1 = 'CLOSED' THEN CURRENT_TIMESTAMP END,
+				required_reviewers = GREATEST(required_reviewers, $4)
+			WHERE id = $2 AND status = $3
+		`
+		result, err := tx.Exec(ctx, query, to, prID, from, requiredReviewers)
+		if err != nil {
+			return fmt.Errorf("ошибка при обновлении статуса пулл реквеста: %v", err)
+		}
+		if result.RowsAffected() == 0 {
+			return errors.New("статус пулл реквеста изменился")
+		}
+
+		return insertReviewers(ctx, tx, prID, reviewers)
+	}
+	err := prr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return prr.GetPRByID(ctx, prID)
+}
+
+// ReplaceReviewer заменяет ревьюера и записывает причину замены в историю переназначений.
+// Отсчет SLA для нового ревьюера начинается заново
+func (prr *prRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string, source models.AssignmentSource, reason models.ReassignReason) error {
 	txFunc := func(tx pgx.Tx) error {
 		var count int
 		err := tx.QueryRow(ctx,
@@ -183,8 +394,8 @@ func (prr *prRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, new
 			}
 		} else {
 			result, err := tx.Exec(ctx,
-				"UPDATE pr_reviewers SET reviewer_id = $1 WHERE pr_id = $2 AND reviewer_id = $3",
-				newReviewerID, prID, oldReviewerID)
+				"UPDATE pr_reviewers SET reviewer_id = $1, assigned_via = $2, assigned_at = now() WHERE pr_id = $3 AND reviewer_id = $4",
+				newReviewerID, source, prID, oldReviewerID)
 			if err != nil {
 				return fmt.Errorf("failed to replace reviewer: %w", err)
 			}
@@ -194,6 +405,13 @@ func (prr *prRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, new
 				return errors.New("NOT_ASSIGNED")
 			}
 		}
+
+		_, err = tx.Exec(ctx,
+			"INSERT INTO pr_reassignments (pr_id, old_reviewer_id, new_reviewer_id, reason) VALUES ($1, $2, $3, $4)",
+			prID, oldReviewerID, newReviewerID, reason)
+		if err != nil {
+			return fmt.Errorf("failed to record reassignment: %w", err)
+		}
 		return nil
 	}
 	err := prr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
@@ -204,6 +422,157 @@ func (prr *prRepo) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, new
 	return nil
 }
 
+func (prr *prRepo) AddReviewer(ctx context.Context, prID, reviewerID string, source models.AssignmentSource) error {
+	query := `
+		INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_via)
+		VALUES ($1, $2, $3)
+	`
+	_, err := prr.db.Exec(ctx, query, prID, reviewerID, source)
+	if err != nil {
+		return fmt.Errorf("ошибка при добавлении ревьюера: %v", err)
+	}
+	return nil
+}
+
+func (prr *prRepo) RemoveReviewer(ctx context.Context, prID, reviewerID string) error {
+	query := `
+		DELETE FROM pr_reviewers
+		WHERE pr_id = $1 AND reviewer_id = $2
+	`
+	result, err := prr.db.Exec(ctx, query, prID, reviewerID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении ревьюера: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("ревьюер не назначен на пулл реквест")
+	}
+	return nil
+}
+
+// GetPendingReviews возвращает назначения на OPEN пулл реквесты, по которым ревьюер не принял решение
+// и которые уже старше SLA ревьюящей команды (целевой или основной команды автора) по календарному
+// времени. Рабочие часы учитываются в сервисе
+func (prr *prRepo) GetPendingReviews(ctx context.Context) ([]models.PendingReview, error) {
+	var pending []models.PendingReview
+
+	query := `
+		SELECT r.pr_id, r.reviewer_id, ts.team_name, r.assigned_at
+		FROM pr_reviewers r
+		JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		LEFT JOIN users a ON a.id = p.author_id
+		JOIN team_settings ts ON ts.team_name = COALESCE(p.target_team, a.team_name)
+		WHERE ts.review_sla_hours > 0
+			AND r.assigned_at <= now() - make_interval(hours => ts.review_sla_hours)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM pr_reviews rv
+				WHERE rv.pr_id = r.pr_id AND rv.reviewer_id = r.reviewer_id AND rv.submitted_at >= r.assigned_at
+			)
+		ORDER BY r.assigned_at
+	`
+	rows, err := prr.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ожидающих ревью: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var review models.PendingReview
+		err := rows.Scan(&review.PRID, &review.ReviewerID, &review.TeamName, &review.AssignedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		pending = append(pending, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return pending, nil
+}
+
+// GetOpenAssignments возвращает OPEN назначения ревьюеров одним запросом, вместе с автором,
+// ревьюящей командой (целевой или основной командой автора), соавторами, метками и полным
+// списком ревьюеров каждого пулл реквеста
+func (prr *prRepo) GetOpenAssignments(ctx context.Context, reviewerIDs []string) ([]models.OpenAssignment, error) {
+	var assignments []models.OpenAssignment
+
+	query := `
+		SELECT r.pr_id, r.reviewer_id, COALESCE(p.author_id, ''), COALESCE(p.target_team, a.team_name, ''), p.labels,
+			ARRAY(SELECT r2.reviewer_id FROM pr_reviewers r2 WHERE r2.pr_id = r.pr_id) AS reviewers,
+			ARRAY(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = r.pr_id) AS co_authors
+		FROM pr_reviewers r
+		JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		LEFT JOIN users a ON a.id = p.author_id
+		WHERE r.reviewer_id = ANY($1)
+		ORDER BY p.created_at, r.pr_id, r.reviewer_id
+	`
+	rows, err := prr.db.Query(ctx, query, reviewerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении назначений: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var assignment models.OpenAssignment
+		err := rows.Scan(&assignment.PRID, &assignment.ReviewerID, &assignment.AuthorID, &assignment.TeamName,
+			&assignment.Labels, &assignment.Reviewers, &assignment.CoAuthors)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return assignments, nil
+}
+
+// GetUnderstaffedPRs возвращает OPEN пулл реквесты, которым назначено меньше ревьюеров, чем требуется,
+// от старых к новым. Непустой teamNames оставляет пулл реквесты, ревьюеров которым подбирают эти
+// команды: ревьюящая команда или команда автора, если из нее запрошен дополнительный ревьюер
+func (prr *prRepo) GetUnderstaffedPRs(ctx context.Context, teamNames []string) ([]models.UnderstaffedPR, error) {
+	var understaffed []models.UnderstaffedPR
+
+	if teamNames == nil {
+		teamNames = []string{}
+	}
+	query := `
+		SELECT p.id, p.name, COALESCE(p.author_id, ''), COALESCE(p.target_team, a.team_name, ''),
+			p.required_reviewers, COUNT(r.reviewer_id) AS assigned_count
+		FROM pull_requests p
+		LEFT JOIN users a ON a.id = p.author_id
+		LEFT JOIN pr_reviewers r ON r.pr_id = p.id
+		WHERE p.status = 'OPEN'
+			AND (cardinality($1::text[]) = 0
+				OR COALESCE(p.target_team, a.team_name) = ANY($1)
+				OR (p.target_team IS NOT NULL AND p.author_team_review AND a.team_name = ANY($1)))
+		GROUP BY p.id, a.team_name
+		HAVING COUNT(r.reviewer_id) < p.required_reviewers
+		ORDER BY p.created_at, p.id
+	`
+	rows, err := prr.db.Query(ctx, query, teamNames)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении пулл реквестов с нехваткой ревьюеров: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var pr models.UnderstaffedPR
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.ReviewTeam, &pr.RequiredReviewers, &pr.AssignedCount)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		understaffed = append(understaffed, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return understaffed, nil
+}
+
 func (prr *prRepo) IsPRMerged(ctx context.Context, prID string) (*bool, error) {
 	var status string
 	query := `
@@ -236,6 +605,7 @@ func (prr *prRepo) GetReviewCountByUser(ctx context.Context) ([]models.UserStats
             u.id,
             u.username,
             COALESCE(review_stats.count, 0) AS review_count,
+            COALESCE(author_stats.count, 0) AS authored_count,
             u.is_active
         FROM users u
         LEFT JOIN (
@@ -243,6 +613,15 @@ func (prr *prRepo) GetReviewCountByUser(ctx context.Context) ([]models.UserStats
             FROM pr_reviewers
             GROUP BY reviewer_id
         ) AS review_stats ON u.id = review_stats.reviewer_id
+        LEFT JOIN (
+            SELECT user_id, COUNT(*) AS count
+            FROM (
+                SELECT author_id AS user_id FROM pull_requests WHERE author_id IS NOT NULL
+                UNION ALL
+                SELECT user_id FROM pr_co_authors
+            ) AS authors
+            GROUP BY user_id
+        ) AS author_stats ON u.id = author_stats.user_id
         ORDER BY review_count DESC, u.username
     `
 
@@ -255,7 +634,7 @@ func (prr *prRepo) GetReviewCountByUser(ctx context.Context) ([]models.UserStats
 	var stats []models.UserStats
 	for rows.Next() {
 		var s models.UserStats
-		err := rows.Scan(&s.UserID, &s.Username, &s.ReviewCount, &s.IsActive)
+		err := rows.Scan(&s.UserID, &s.Username, &s.ReviewCount, &s.AuthoredCount, &s.IsActive)
 		if err != nil {
 			return nil, err
 		}
@@ -263,3 +642,42 @@ func (prr *prRepo) GetReviewCountByUser(ctx context.Context) ([]models.UserStats
 	}
 	return stats, nil
 }
+
+// GetCrossTeamStats возвращает вклад в кодовую базу чужих команд по целевой команде,
+// самые востребованные команды идут первыми. Соавторы считаются участниками наравне с автором
+func (prr *prRepo) GetCrossTeamStats(ctx context.Context) ([]models.CrossTeamStats, error) {
+	var stats []models.CrossTeamStats
+
+	query := `
+		SELECT p.target_team, COUNT(DISTINCT p.id) AS pr_count, COUNT(DISTINCT p.id) FILTER (WHERE p.status = 'MERGED'),
+			COUNT(DISTINCT a.user_id)
+		FROM pull_requests p
+		CROSS JOIN LATERAL (
+			SELECT p.author_id AS user_id
+			UNION
+			SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = p.id
+		) AS a
+		WHERE p.target_team IS NOT NULL
+		GROUP BY p.target_team
+		ORDER BY pr_count DESC, p.target_team
+	`
+	rows, err := prr.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении статистики вклада в другие команды: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var s models.CrossTeamStats
+		err := rows.Scan(&s.TargetTeam, &s.PRCount, &s.MergedCount, &s.Contributors)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return stats, nil
+}
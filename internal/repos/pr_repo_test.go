@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/repos"
@@ -26,18 +27,19 @@ func TestPRRepo_CreatePR(t *testing.T) {
 		Name:              "test_pr",
 		AuthorID:          "userid1",
 		AssignedReviewers: []string{"userid2", "userid3"},
+		Labels:            []string{"Backend", "backend"},
 		Status:            models.StatusOpen,
 	}
 
 	t.Run("успешное создание пулл реквеста с ревьюерами", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id\) VALUES \(\$1, \$2, \$3\)`).
-			WithArgs(pr.ID, pr.Name, pr.AuthorID).
+		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id, status, codeowner_approval_required, labels, repository, changed_files, target_team, author_team_review, required_reviewers\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, NULLIF\(\$9, ''\), \$10, \$11\)`).
+			WithArgs(pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CodeOwnerApprovalRequired, []string{"backend"}, "", []string{}, "", false, pr.RequiredReviewers).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		for _, reviewerID := range pr.AssignedReviewers {
-			mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id\) VALUES \(\$1, \$2\)`).
-				WithArgs(pr.ID, reviewerID).
+			mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id, assigned_via\) VALUES \(\$1, \$2, \$3\)`).
+				WithArgs(pr.ID, reviewerID, models.SourceTeam).
 				WillReturnResult(pgxmock.NewResult("INSERT", 1))
 		}
 		mock.ExpectCommit()
@@ -50,8 +52,8 @@ func TestPRRepo_CreatePR(t *testing.T) {
 
 	t.Run("ошибка при создании пулл реквеста", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id\) VALUES \(\$1, \$2, \$3\)`).
-			WithArgs(pr.ID, pr.Name, pr.AuthorID).
+		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id, status, codeowner_approval_required, labels, repository, changed_files, target_team, author_team_review, required_reviewers\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, NULLIF\(\$9, ''\), \$10, \$11\)`).
+			WithArgs(pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CodeOwnerApprovalRequired, []string{"backend"}, "", []string{}, "", false, pr.RequiredReviewers).
 			WillReturnError(errors.New("ошибка базы данных"))
 		mock.ExpectRollback()
 
@@ -62,18 +64,38 @@ func TestPRRepo_CreatePR(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("соавторы сохраняются вместе с пулл реквестом", func(t *testing.T) {
+		coAuthored := *pr
+		coAuthored.CoAuthors = []string{"userid4", "userid5"}
+		coAuthored.AssignedReviewers = nil
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO pull_requests`).
+			WithArgs(pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CodeOwnerApprovalRequired, []string{"backend"}, "", []string{}, "", false, pr.RequiredReviewers).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`INSERT INTO pr_co_authors \(pr_id, user_id\) SELECT \$1, unnest\(\$2::text\[\]\)`).
+			WithArgs(pr.ID, coAuthored.CoAuthors).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		err := repo.CreatePR(ctx, &coAuthored)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("ошибка при добавлении ревьюера", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id\) VALUES \(\$1, \$2, \$3\)`).
-			WithArgs(pr.ID, pr.Name, pr.AuthorID).
+		mock.ExpectExec(`INSERT INTO pull_requests \(id, name, author_id, status, codeowner_approval_required, labels, repository, changed_files, target_team, author_team_review, required_reviewers\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, NULLIF\(\$9, ''\), \$10, \$11\)`).
+			WithArgs(pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CodeOwnerApprovalRequired, []string{"backend"}, "", []string{}, "", false, pr.RequiredReviewers).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-		mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id\) VALUES \(\$1, \$2\)`).
-			WithArgs(pr.ID, pr.AssignedReviewers[0]).
+		mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id, assigned_via\) VALUES \(\$1, \$2, \$3\)`).
+			WithArgs(pr.ID, pr.AssignedReviewers[0], models.SourceTeam).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-		mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id\) VALUES \(\$1, \$2\)`).
-			WithArgs(pr.ID, pr.AssignedReviewers[1]).
+		mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id, assigned_via\) VALUES \(\$1, \$2, \$3\)`).
+			WithArgs(pr.ID, pr.AssignedReviewers[1], models.SourceTeam).
 			WillReturnError(errors.New("ошибка добавления ревьюера"))
 		mock.ExpectRollback()
 
@@ -98,16 +120,32 @@ func TestPRRepo_GetPRByID(t *testing.T) {
 
 	t.Run("успешное получение пулл реквеста", func(t *testing.T) {
 		expectedPR := &models.PullRequest{
-			ID:       prID,
-			Name:     "test_pr",
-			AuthorID: "userid1",
-			Status:   models.StatusOpen,
+			ID:                prID,
+			Name:              "test_pr",
+			AuthorID:          "userid1",
+			CoAuthors:         []string{"userid4"},
+			Status:            models.StatusOpen,
+			AssignedReviewers: []string{"userid2", "userid3"},
+			Assignments: []models.ReviewerAssignment{
+				{ReviewerID: "userid2", Source: models.SourceCodeOwner},
+				{ReviewerID: "userid3", Source: models.SourceTeam},
+			},
+			CodeOwnerApprovalRequired: true,
+			Labels:                    []string{"go"},
+			ChangedFiles:              []string{},
 		}
 
-		mock.ExpectQuery(`SELECT id, name, author_id, status FROM pull_requests WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
+			WithArgs(prID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "author_id", "status", "codeowner_approval_required", "labels", "force_merged", "repository", "changed_files", "created_at", "merged_at", "closed_at", "target_team", "author_team_review", "required_reviewers", "co_authors"}).
+				AddRow(expectedPR.ID, expectedPR.Name, expectedPR.AuthorID, expectedPR.Status, true, expectedPR.Labels, false,
+					"", []string{}, nil, nil, nil, "", false, 0, expectedPR.CoAuthors))
+
+		mock.ExpectQuery(`SELECT reviewer_id, assigned_via FROM pr_reviewers WHERE pr_id = \$1`).
 			WithArgs(prID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "author_id", "status"}).
-				AddRow(expectedPR.ID, expectedPR.Name, expectedPR.AuthorID, expectedPR.Status))
+			WillReturnRows(pgxmock.NewRows([]string{"reviewer_id", "assigned_via"}).
+				AddRow("userid2", models.SourceCodeOwner).
+				AddRow("userid3", models.SourceTeam))
 
 		pr, err := repo.GetPRByID(ctx, prID)
 
@@ -117,7 +155,7 @@ func TestPRRepo_GetPRByID(t *testing.T) {
 	})
 
 	t.Run("пулл реквест не найден", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, name, author_id, status FROM pull_requests WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
 			WithArgs(prID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -130,7 +168,7 @@ func TestPRRepo_GetPRByID(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, name, author_id, status FROM pull_requests WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
 			WithArgs(prID).
 			WillReturnError(errors.New("ошибка базы данных"))
 
@@ -153,19 +191,20 @@ func TestPRRepo_GetPRsByReviewer(t *testing.T) {
 
 	ctx := context.Background()
 	userID := "userid1"
+	query := `SELECT p\.id, p\.name, COALESCE\(p\.author_id, ''\), p\.status FROM pull_requests p JOIN pr_reviewers r ON p\.id = r\.pr_id WHERE r\.reviewer_id = \$1 AND \(cardinality\(\$2::text\[\]\) = 0 OR p\.status = ANY\(\$2\)\)`
 
 	t.Run("успешное получение пулл реквестов по ревьюеру", func(t *testing.T) {
-		expectedPRs := []models.PullRequest{
+		expectedPRs := []models.PullRequestShort{
 			{
 				ID:       "pr-0001",
 				Name:     "pr_1",
-				AuthorID: "userid1",
+				AuthorID: "userid2",
 				Status:   models.StatusOpen,
 			},
 			{
 				ID:       "pr-0002",
 				Name:     "pr_2",
-				AuthorID: "userid1",
+				AuthorID: "userid2",
 				Status:   models.StatusMerged,
 			},
 		}
@@ -174,42 +213,172 @@ func TestPRRepo_GetPRsByReviewer(t *testing.T) {
 			AddRow(expectedPRs[0].ID, expectedPRs[0].Name, expectedPRs[0].AuthorID, expectedPRs[0].Status).
 			AddRow(expectedPRs[1].ID, expectedPRs[1].Name, expectedPRs[1].AuthorID, expectedPRs[1].Status)
 
-		mock.ExpectQuery(`SELECT p\.id, p\.name, p\.author_id, p\.status FROM pull_requests p JOIN pr_reviewers r ON p\.id = r\.pr_id WHERE r\.reviewer_id = \$1`).
-			WithArgs(userID).
+		mock.ExpectQuery(query).
+			WithArgs(userID, []string{}).
 			WillReturnRows(rows)
 
-		prs, err := repo.GetPRsByReviewer(ctx, userID)
+		prs, err := repo.GetPRsByReviewer(ctx, userID, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPRs, prs)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("фильтр по статусам", func(t *testing.T) {
+		rows := pgxmock.NewRows([]string{"id", "name", "author_id", "status"}).
+			AddRow("pr-0001", "pr_1", "userid2", models.StatusOpen)
+
+		mock.ExpectQuery(query).
+			WithArgs(userID, []string{"OPEN", "MERGED"}).
+			WillReturnRows(rows)
+
+		prs, err := repo.GetPRsByReviewer(ctx, userID, []models.Status{models.StatusOpen, models.StatusMerged})
+
+		assert.NoError(t, err)
+		assert.Len(t, prs, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT p\.id, p\.name, p\.author_id, p\.status FROM pull_requests p JOIN pr_reviewers r ON p\.id = r\.pr_id WHERE r\.reviewer_id = \$1`).
-			WithArgs(userID).
+		mock.ExpectQuery(query).
+			WithArgs(userID, []string{}).
 			WillReturnError(errors.New("ошибка базы данных"))
 
-		prs, err := repo.GetPRsByReviewer(ctx, userID)
+		prs, err := repo.GetPRsByReviewer(ctx, userID, nil)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "ошибка при получении пулл реквестов")
 		assert.Nil(t, prs)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+}
 
-	t.Run("ошибка при сканировании строки", func(t *testing.T) {
-		rows := pgxmock.NewRows([]string{"id", "name", "author_id", "status"}).
-			AddRow("invalid-id", "pr_1", "userid1", models.StatusOpen)
+func TestPRRepo_TransitionPR(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
 
-		mock.ExpectQuery(`SELECT p\.id, p\.name, p\.author_id, p\.status FROM pull_requests p JOIN pr_reviewers r ON p\.id = r\.pr_id WHERE r\.reviewer_id = \$1`).
-			WithArgs(userID).
-			WillReturnRows(rows)
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
 
-		prs, err := repo.GetPRsByReviewer(ctx, userID)
+	ctx := context.Background()
+	prID := "pr-0001"
+	update := `UPDATE pull_requests SET status = \$1, closed_at = CASE WHEN \$1 = 'CLOSED' THEN CURRENT_TIMESTAMP END, required_reviewers = GREATEST\(required_reviewers, \$4\) WHERE id = \$2 AND status = \$3`
+
+	t.Run("черновик готов к ревью, ревьюеры назначаются в той же транзакции", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(update).
+			WithArgs(models.StatusOpen, prID, models.StatusDraft, 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reviewers \(pr_id, reviewer_id, assigned_via\) VALUES \(\$1, \$2, \$3\)`).
+			WithArgs(prID, "userid2", models.SourceTeam).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
+			WithArgs(prID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "author_id", "status", "codeowner_approval_required", "labels", "force_merged", "repository", "changed_files", "created_at", "merged_at", "closed_at", "target_team", "author_team_review", "required_reviewers", "co_authors"}).
+				AddRow(prID, "test_pr", "userid1", models.StatusOpen, false, []string{}, false, "", []string{}, nil, nil, nil, "", false, 0, []string{}))
+		mock.ExpectQuery(`SELECT reviewer_id, assigned_via FROM pr_reviewers WHERE pr_id = \$1`).
+			WithArgs(prID).
+			WillReturnRows(pgxmock.NewRows([]string{"reviewer_id", "assigned_via"}).
+				AddRow("userid2", models.SourceTeam))
+
+		pr, err := repo.TransitionPR(ctx, prID, models.StatusDraft, models.StatusOpen,
+			[]models.ReviewerAssignment{{ReviewerID: "userid2", Source: models.SourceTeam}}, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.StatusOpen, pr.Status)
+		assert.Equal(t, []string{"userid2"}, pr.AssignedReviewers)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("статус изменился параллельно", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(update).
+			WithArgs(models.StatusClosed, prID, models.StatusOpen, 0).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		pr, err := repo.TransitionPR(ctx, prID, models.StatusOpen, models.StatusClosed, nil, 0)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "ошибка при скане строки")
+		assert.Equal(t, "статус пулл реквеста изменился", err.Error())
+		assert.Nil(t, pr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_ListPRs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	columns := []string{"id", "name", "author_id", "status", "codeowner_approval_required", "labels", "force_merged", "repository", "changed_files", "created_at", "merged_at", "closed_at", "target_team", "author_team_review", "required_reviewers", "co_authors"}
+	createdAt := time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC)
+
+	t.Run("фильтры и ревьюеры страницы", func(t *testing.T) {
+		filter := models.PRListFilter{
+			Statuses:     []models.Status{models.StatusOpen},
+			TeamName:     "backend",
+			NameContains: "100%_fix",
+			SortBy:       models.SortByCreatedAt,
+			Limit:        3,
+		}
+
+		mock.ExpectQuery(`FROM pull_requests p WHERE .* ORDER BY p\.created_at ASC, p\.id ASC LIMIT \$10`).
+			WithArgs([]string{"OPEN"}, "", "", "backend", `100\%\_fix`, filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo, 3).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("pr-0001", "100%_fix", "userid1", models.StatusOpen, false, []string{}, false, "", []string{}, &createdAt, nil, nil, "", false, 0, []string{}).
+				AddRow("pr-0002", "100%_fix again", "userid1", models.StatusOpen, false, []string{}, false, "", []string{}, &createdAt, nil, nil, "", false, 0, []string{}))
+		mock.ExpectQuery(`SELECT pr_id, reviewer_id, assigned_via FROM pr_reviewers WHERE pr_id = ANY\(\$1\)`).
+			WithArgs([]string{"pr-0001", "pr-0002"}).
+			WillReturnRows(pgxmock.NewRows([]string{"pr_id", "reviewer_id", "assigned_via"}).
+				AddRow("pr-0001", "userid2", models.SourceTeam).
+				AddRow("pr-0002", "userid3", models.SourceCodeOwner))
+
+		prs, err := repo.ListPRs(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Len(t, prs, 2)
+		assert.Equal(t, []string{"userid2"}, prs[0].AssignedReviewers)
+		assert.Equal(t, []models.ReviewerAssignment{{ReviewerID: "userid3", Source: models.SourceCodeOwner}}, prs[1].Assignments)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("следующая страница по merged_at в обратном порядке", func(t *testing.T) {
+		mergedAt := createdAt.Add(time.Hour)
+		filter := models.PRListFilter{
+			SortBy: models.SortByMergedAt,
+			Desc:   true,
+			Limit:  2,
+			After:  &models.PRCursor{SortBy: models.SortByMergedAt, Desc: true, Time: &mergedAt, ID: "pr-0005"},
+		}
+
+		mock.ExpectQuery(`AND p\.merged_at IS NOT NULL AND \(p\.merged_at, p\.id\) < \(\$11, \$12\) ORDER BY p\.merged_at DESC, p\.id DESC`).
+			WithArgs([]string{}, "", "", "", "", filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo, 2, &mergedAt, "pr-0005").
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		prs, err := repo.ListPRs(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Empty(t, prs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(`FROM pull_requests p`).
+			WithArgs([]string{}, "", "", "", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), 1).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		prs, err := repo.ListPRs(ctx, models.PRListFilter{Limit: 1})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении пулл реквестов")
 		assert.Nil(t, prs)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -229,20 +398,28 @@ func TestPRRepo_UpdatePRStatus(t *testing.T) {
 
 	t.Run("успешное обновление статуса пулл реквеста", func(t *testing.T) {
 		updatedPR := &models.PullRequest{
-			ID:       prID,
-			Name:     "upd_pr",
-			AuthorID: "userid1",
-			Status:   status,
+			ID:           prID,
+			Name:         "upd_pr",
+			AuthorID:     "userid1",
+			CoAuthors:    []string{},
+			Status:       status,
+			Labels:       []string{},
+			ChangedFiles: []string{},
 		}
 
-		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE pull_request_id = \$2`).
+		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE id = \$2`).
 			WithArgs(status, prID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		mock.ExpectQuery(`SELECT id, name, author_id, status FROM pull_requests WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
+			WithArgs(prID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "author_id", "status", "codeowner_approval_required", "labels", "force_merged", "repository", "changed_files", "created_at", "merged_at", "closed_at", "target_team", "author_team_review", "required_reviewers", "co_authors"}).
+				AddRow(updatedPR.ID, updatedPR.Name, updatedPR.AuthorID, updatedPR.Status, false, updatedPR.Labels, false,
+					"", []string{}, nil, nil, nil, "", false, 0, []string{}))
+
+		mock.ExpectQuery(`SELECT reviewer_id, assigned_via FROM pr_reviewers WHERE pr_id = \$1`).
 			WithArgs(prID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "author_id", "status"}).
-				AddRow(updatedPR.ID, updatedPR.Name, updatedPR.AuthorID, updatedPR.Status))
+			WillReturnRows(pgxmock.NewRows([]string{"reviewer_id", "assigned_via"}))
 
 		pr, err := repo.UpdatePRStatus(ctx, prID, status)
 
@@ -252,7 +429,7 @@ func TestPRRepo_UpdatePRStatus(t *testing.T) {
 	})
 
 	t.Run("ошибка при обновлении статуса", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE pull_request_id = \$2`).
+		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE id = \$2`).
 			WithArgs(status, prID).
 			WillReturnError(errors.New("ошибка обновления"))
 
@@ -265,11 +442,11 @@ func TestPRRepo_UpdatePRStatus(t *testing.T) {
 	})
 
 	t.Run("ошибка при получении обновленного пулл реквеста", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE pull_request_id = \$2`).
+		mock.ExpectExec(`UPDATE pull_requests SET status = \$1, merged_at = CASE WHEN \$1 = 'MERGED' THEN CURRENT_TIMESTAMP ELSE merged_at END WHERE id = \$2`).
 			WithArgs(status, prID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		mock.ExpectQuery(`SELECT id, name, author_id, status FROM pull_requests WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, COALESCE\(author_id, ''\), status, codeowner_approval_required, labels, force_merged, repository, changed_files, created_at, merged_at, closed_at, COALESCE\(target_team, ''\), author_team_review, required_reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = pull_requests.id ORDER BY c.user_id\) AS co_authors FROM pull_requests WHERE id = \$1`).
 			WithArgs(prID).
 			WillReturnError(errors.New("ошибка получения"))
 
@@ -295,26 +472,211 @@ func TestPRRepo_ReplaceReviewer(t *testing.T) {
 	oldReviewerID := "userid1"
 	newReviewerID := "userid2"
 
+	countQuery := `SELECT COUNT\(\*\) FROM pr_reviewers WHERE pr_id = \$1 AND reviewer_id = \$2`
+
 	t.Run("успешная замена ревьюера", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE pr_reviewers SET reviewer_id = \$1 WHERE pr_id = \$2 AND reviewer_id = \$3`).
-			WithArgs(newReviewerID, prID, oldReviewerID).
+		mock.ExpectBegin()
+		mock.ExpectQuery(countQuery).
+			WithArgs(prID, oldReviewerID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(countQuery).
+			WithArgs(prID, newReviewerID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`UPDATE pr_reviewers SET reviewer_id = \$1, assigned_via = \$2, assigned_at = now\(\) WHERE pr_id = \$3 AND reviewer_id = \$4`).
+			WithArgs(newReviewerID, models.SourceTeam, prID, oldReviewerID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments \(pr_id, old_reviewer_id, new_reviewer_id, reason\) VALUES \(\$1, \$2, \$3, \$4\)`).
+			WithArgs(prID, oldReviewerID, newReviewerID, models.ReasonSLAExpired).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
 
-		err := repo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID)
+		err := repo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID, models.SourceTeam, models.ReasonSLAExpired)
 
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("старый ревьюер не назначен", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(countQuery).
+			WithArgs(prID, oldReviewerID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		err := repo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID, models.SourceTeam, models.ReasonManual)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "NOT_ASSIGNED")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("ошибка при замене ревьюера", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE pr_reviewers SET reviewer_id = \$1 WHERE pr_id = \$2 AND reviewer_id = \$3`).
-			WithArgs(newReviewerID, prID, oldReviewerID).
+		mock.ExpectBegin()
+		mock.ExpectQuery(countQuery).
+			WithArgs(prID, oldReviewerID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(countQuery).
+			WithArgs(prID, newReviewerID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`UPDATE pr_reviewers SET reviewer_id`).
+			WithArgs(newReviewerID, models.SourceManual, prID, oldReviewerID).
 			WillReturnError(errors.New("ошибка базы данных"))
+		mock.ExpectRollback()
 
-		err := repo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID)
+		err := repo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID, models.SourceManual, models.ReasonManual)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "не удалось заменить ревьюера")
+		assert.Contains(t, err.Error(), "failed to replace reviewer")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_GetPendingReviews(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT r.pr_id, r.reviewer_id, ts.team_name, r.assigned_at FROM pr_reviewers r JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN' LEFT JOIN users a ON a.id = p.author_id JOIN team_settings ts ON ts.team_name = COALESCE\(p.target_team, a.team_name\)`
+	assignedAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("просроченные назначения", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(pgxmock.NewRows([]string{"pr_id", "reviewer_id", "team_name", "assigned_at"}).
+				AddRow("pr-0001", "userid2", "backend", assignedAt))
+
+		pending, err := repo.GetPendingReviews(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.PendingReview{
+			{PRID: "pr-0001", ReviewerID: "userid2", TeamName: "backend", AssignedAt: assignedAt},
+		}, pending)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		pending, err := repo.GetPendingReviews(ctx)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении ожидающих ревью")
+		assert.Nil(t, pending)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_GetOpenAssignments(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	reviewerIDs := []string{"userid1", "userid2"}
+	query := `SELECT r.pr_id, r.reviewer_id, COALESCE\(p.author_id, ''\), COALESCE\(p.target_team, a.team_name, ''\), p.labels, ARRAY\(SELECT r2.reviewer_id FROM pr_reviewers r2 WHERE r2.pr_id = r.pr_id\) AS reviewers, ARRAY\(SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = r.pr_id\) AS co_authors FROM pr_reviewers r`
+
+	t.Run("назначения уходящих ревьюеров", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(reviewerIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"pr_id", "reviewer_id", "author_id", "team_name", "labels", "reviewers", "co_authors"}).
+				AddRow("pr-0001", "userid1", "userid5", "backend", []string{"go"}, []string{"userid1", "userid3"}, []string{"userid6"}))
+
+		assignments, err := repo.GetOpenAssignments(ctx, reviewerIDs)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.OpenAssignment{
+			{PRID: "pr-0001", ReviewerID: "userid1", AuthorID: "userid5", CoAuthors: []string{"userid6"}, TeamName: "backend", Labels: []string{"go"}, Reviewers: []string{"userid1", "userid3"}},
+		}, assignments)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(reviewerIDs).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		assignments, err := repo.GetOpenAssignments(ctx, reviewerIDs)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении назначений")
+		assert.Nil(t, assignments)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_AddReviewer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	query := `INSERT INTO pr_reviewers \(pr_id, reviewer_id, assigned_via\) VALUES \(\$1, \$2, \$3\)`
+
+	t.Run("успешное добавление ревьюера", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("pr-0001", "userid2", models.SourceManual).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.AddReviewer(ctx, "pr-0001", "userid2", models.SourceManual)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("pr-0001", "userid2", models.SourceTeam).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		err := repo.AddReviewer(ctx, "pr-0001", "userid2", models.SourceTeam)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при добавлении ревьюера")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_RemoveReviewer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	query := `DELETE FROM pr_reviewers WHERE pr_id = \$1 AND reviewer_id = \$2`
+
+	t.Run("успешное удаление ревьюера", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("pr-0001", "userid2").
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		err := repo.RemoveReviewer(ctx, "pr-0001", "userid2")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ревьюер не назначен", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("pr-0001", "userid2").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err := repo.RemoveReviewer(ctx, "pr-0001", "userid2")
+
+		assert.Error(t, err)
+		assert.Equal(t, "ревьюер не назначен на пулл реквест", err.Error())
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -386,3 +748,96 @@ func TestPRRepo_IsPRMerged(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestPRRepo_GetCrossTeamStats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT p.target_team, COUNT\(DISTINCT p.id\) AS pr_count, COUNT\(DISTINCT p.id\) FILTER \(WHERE p.status = 'MERGED'\), COUNT\(DISTINCT a.user_id\) FROM pull_requests p CROSS JOIN LATERAL \( SELECT p.author_id AS user_id UNION SELECT c.user_id FROM pr_co_authors c WHERE c.pr_id = p.id \) AS a WHERE p.target_team IS NOT NULL GROUP BY p.target_team`
+
+	t.Run("вклад в другие команды по целевой команде", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(pgxmock.NewRows([]string{"target_team", "pr_count", "merged_count", "contributors"}).
+				AddRow("payments", 5, 3, 2).
+				AddRow("platform", 1, 0, 1))
+
+		stats, err := repo.GetCrossTeamStats(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.CrossTeamStats{
+			{TargetTeam: "payments", PRCount: 5, MergedCount: 3, Contributors: 2},
+			{TargetTeam: "platform", PRCount: 1, MergedCount: 0, Contributors: 1},
+		}, stats)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		stats, err := repo.GetCrossTeamStats(ctx)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении статистики вклада в другие команды")
+		assert.Nil(t, stats)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPRRepo_GetUnderstaffedPRs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewPRRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT p.id, p.name, COALESCE\(p.author_id, ''\), COALESCE\(p.target_team, a.team_name, ''\), p.required_reviewers, COUNT\(r.reviewer_id\) AS assigned_count FROM pull_requests p .* HAVING COUNT\(r.reviewer_id\) < p.required_reviewers`
+	columns := []string{"id", "name", "author_id", "review_team", "required_reviewers", "assigned_count"}
+
+	t.Run("пулл реквесты команды с нехваткой ревьюеров", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]string{"backend"}).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("pr-0001", "test_pr", "userid1", "backend", 2, 0))
+
+		understaffed, err := repo.GetUnderstaffedPRs(ctx, []string{"backend"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.UnderstaffedPR{
+			{ID: "pr-0001", Name: "test_pr", AuthorID: "userid1", ReviewTeam: "backend", RequiredReviewers: 2, AssignedCount: 0},
+		}, understaffed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("без фильтра по командам", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]string{}).
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		understaffed, err := repo.GetUnderstaffedPRs(ctx, nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, understaffed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]string{"backend"}).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		understaffed, err := repo.GetUnderstaffedPRs(ctx, []string{"backend"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении пулл реквестов с нехваткой ревьюеров")
+		assert.Nil(t, understaffed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,72 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+type ReviewRepo interface {
+	CreateReview(ctx context.Context, review *models.Review) (*models.Review, error)
+	GetLatestReviews(ctx context.Context, prID string) ([]models.Review, error)
+}
+
+type reviewRepo struct {
+	db DBInterface
+}
+
+func NewReviewRepo(db DBInterface) ReviewRepo {
+	return &reviewRepo{
+		db: db,
+	}
+}
+
+func (rr *reviewRepo) CreateReview(ctx context.Context, review *models.Review) (*models.Review, error) {
+	var created models.Review
+
+	query := `
+		INSERT INTO pr_reviews (pr_id, reviewer_id, decision, comment)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, pr_id, reviewer_id, decision, comment, submitted_at
+	`
+	row := rr.db.QueryRow(ctx, query, review.PRID, review.ReviewerID, review.Decision, review.Comment)
+	err := row.Scan(&created.ID, &created.PRID, &created.ReviewerID, &created.Decision, &created.Comment, &created.SubmittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении ревью: %v", err)
+	}
+	return &created, nil
+}
+
+// GetLatestReviews возвращает последнее решение каждого ревьюера, который сейчас назначен на пулл реквест
+func (rr *reviewRepo) GetLatestReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	var reviews []models.Review
+
+	query := `
+		SELECT DISTINCT ON (rv.reviewer_id)
+			rv.id, rv.pr_id, rv.reviewer_id, rv.decision, rv.comment, rv.submitted_at
+		FROM pr_reviews rv
+		JOIN pr_reviewers r ON r.pr_id = rv.pr_id AND r.reviewer_id = rv.reviewer_id
+		WHERE rv.pr_id = $1
+		ORDER BY rv.reviewer_id, rv.submitted_at DESC, rv.id DESC
+	`
+	rows, err := rr.db.Query(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ревью: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var review models.Review
+		err := rows.Scan(&review.ID, &review.PRID, &review.ReviewerID, &review.Decision, &review.Comment, &review.SubmittedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return reviews, nil
+}
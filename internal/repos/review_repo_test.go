@@ -0,0 +1,107 @@
+package repos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewRepo_CreateReview(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewRepo(db)
+
+	ctx := context.Background()
+	review := &models.Review{
+		PRID:       "prid1",
+		ReviewerID: "userid2",
+		Decision:   models.DecisionApproved,
+		Comment:    "lgtm",
+	}
+	submittedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("успешное сохранение ревью", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO pr_reviews \(pr_id, reviewer_id, decision, comment\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id, pr_id, reviewer_id, decision, comment, submitted_at`).
+			WithArgs(review.PRID, review.ReviewerID, review.Decision, review.Comment).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "pr_id", "reviewer_id", "decision", "comment", "submitted_at"}).
+				AddRow(int64(1), review.PRID, review.ReviewerID, review.Decision, review.Comment, &submittedAt))
+
+		created, err := repo.CreateReview(ctx, review)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.Review{
+			ID:          1,
+			PRID:        review.PRID,
+			ReviewerID:  review.ReviewerID,
+			Decision:    review.Decision,
+			Comment:     review.Comment,
+			SubmittedAt: &submittedAt,
+		}, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO pr_reviews`).
+			WithArgs(review.PRID, review.ReviewerID, review.Decision, review.Comment).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		created, err := repo.CreateReview(ctx, review)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при сохранении ревью")
+		assert.Nil(t, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReviewRepo_GetLatestReviews(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewRepo(db)
+
+	ctx := context.Background()
+	prID := "prid1"
+	query := `SELECT DISTINCT ON \(rv.reviewer_id\) rv.id, rv.pr_id, rv.reviewer_id, rv.decision, rv.comment, rv.submitted_at FROM pr_reviews rv JOIN pr_reviewers r`
+	submittedAt := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("последние решения ревьюеров", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(prID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "pr_id", "reviewer_id", "decision", "comment", "submitted_at"}).
+				AddRow(int64(3), prID, "userid2", models.DecisionApproved, "", &submittedAt).
+				AddRow(int64(2), prID, "userid3", models.DecisionChangesRequested, "поправь тесты", &submittedAt))
+
+		reviews, err := repo.GetLatestReviews(ctx, prID)
+
+		assert.NoError(t, err)
+		assert.Len(t, reviews, 2)
+		assert.Equal(t, models.DecisionApproved, reviews[0].Decision)
+		assert.Equal(t, "userid3", reviews[1].ReviewerID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(prID).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		reviews, err := repo.GetLatestReviews(ctx, prID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении ревью")
+		assert.Nil(t, reviews)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
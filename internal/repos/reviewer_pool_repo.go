@@ -0,0 +1,210 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReviewerPoolRepo interface {
+	SavePool(ctx context.Context, poolName string, userIDs []string) error
+	GetPool(ctx context.Context, poolName string) (*models.ReviewerPool, error)
+	ListPools(ctx context.Context) ([]models.ReviewerPool, error)
+	DeletePool(ctx context.Context, poolName string) error
+	SetTeamPool(ctx context.Context, teamPool *models.TeamPool) error
+	RemoveTeamPool(ctx context.Context, teamName, poolName string) error
+	GetTeamPools(ctx context.Context, teamName string) ([]models.TeamPool, error)
+}
+
+type reviewerPoolRepo struct {
+	db DBInterface
+}
+
+func NewReviewerPoolRepo(db DBInterface) ReviewerPoolRepo {
+	return &reviewerPoolRepo{
+		db: db,
+	}
+}
+
+// SavePool создает пул, если его нет, и заменяет его состав на userIDs
+func (rpr *reviewerPoolRepo) SavePool(ctx context.Context, poolName string, userIDs []string) error {
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+
+	txFunc := func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO reviewer_pools (name) VALUES ($1) ON CONFLICT DO NOTHING`, poolName)
+		if err != nil {
+			return fmt.Errorf("не удалось создать пул %v: %v", poolName, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			DELETE FROM reviewer_pool_members
+			WHERE pool_name = $1 AND NOT (user_id = ANY($2))
+		`, poolName, userIDs)
+		if err != nil {
+			return fmt.Errorf("не удалось удалить участников пула %v: %v", poolName, err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO reviewer_pool_members (pool_name, user_id)
+			SELECT $1, unnest($2::text[])
+			ON CONFLICT DO NOTHING
+		`, poolName, userIDs)
+		if err != nil {
+			return fmt.Errorf("не удалось добавить участников пула %v: %v", poolName, err)
+		}
+		return nil
+	}
+	err := rpr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка транзакции при сохранении пула: %v", err)
+	}
+	return nil
+}
+
+func (rpr *reviewerPoolRepo) GetPool(ctx context.Context, poolName string) (*models.ReviewerPool, error) {
+	query := `
+		SELECT rp.name, u.id, u.username, COALESCE(u.team_name, ''), u.is_active
+		FROM reviewer_pools rp
+		LEFT JOIN reviewer_pool_members pm ON pm.pool_name = rp.name
+		LEFT JOIN users u ON u.id = pm.user_id
+		WHERE rp.name = $1
+		ORDER BY u.id
+	`
+	pools, err := rpr.queryPools(ctx, query, poolName)
+	if err != nil {
+		return nil, err
+	}
+	if len(pools) == 0 {
+		return nil, errors.New("пул не найден")
+	}
+	return &pools[0], nil
+}
+
+// ListPools возвращает все пулы с участниками, упорядоченные по имени
+func (rpr *reviewerPoolRepo) ListPools(ctx context.Context) ([]models.ReviewerPool, error) {
+	query := `
+		SELECT rp.name, u.id, u.username, COALESCE(u.team_name, ''), u.is_active
+		FROM reviewer_pools rp
+		LEFT JOIN reviewer_pool_members pm ON pm.pool_name = rp.name
+		LEFT JOIN users u ON u.id = pm.user_id
+		ORDER BY rp.name, u.id
+	`
+	return rpr.queryPools(ctx, query)
+}
+
+func (rpr *reviewerPoolRepo) queryPools(ctx context.Context, query string, args ...any) ([]models.ReviewerPool, error) {
+	var pools []models.ReviewerPool
+
+	rows, err := rpr.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пулы ревьюеров: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var userID, username, teamName *string
+		var isActive *bool
+		err := rows.Scan(&name, &userID, &username, &teamName, &isActive)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка скана строки пула: %v", err)
+		}
+
+		if len(pools) == 0 || pools[len(pools)-1].Name != name {
+			pools = append(pools, models.ReviewerPool{Name: name, Members: []models.User{}})
+		}
+		// у пула без участников LEFT JOIN дает одну строку с NULL
+		if userID != nil {
+			pool := &pools[len(pools)-1]
+			pool.Members = append(pool.Members, models.User{
+				ID:       *userID,
+				Username: *username,
+				TeamName: *teamName,
+				IsActive: *isActive,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка скана строк: %v", err)
+	}
+	return pools, nil
+}
+
+// DeletePool удаляет пул, его состав и подключения к командам
+func (rpr *reviewerPoolRepo) DeletePool(ctx context.Context, poolName string) error {
+	result, err := rpr.db.Exec(ctx, `DELETE FROM reviewer_pools WHERE name = $1`, poolName)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить пул %v: %v", poolName, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("пул не найден")
+	}
+	return nil
+}
+
+// SetTeamPool подключает пул к команде, у уже подключенного пула меняется режим
+func (rpr *reviewerPoolRepo) SetTeamPool(ctx context.Context, teamPool *models.TeamPool) error {
+	query := `
+		INSERT INTO team_reviewer_pools (team_name, pool_name, mode)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_name, pool_name)
+		DO UPDATE SET mode = EXCLUDED.mode
+	`
+	_, err := rpr.db.Exec(ctx, query, teamPool.TeamName, teamPool.PoolName, teamPool.Mode)
+	if err != nil {
+		return fmt.Errorf("не удалось подключить пул %v к команде %v: %v", teamPool.PoolName, teamPool.TeamName, err)
+	}
+	return nil
+}
+
+func (rpr *reviewerPoolRepo) RemoveTeamPool(ctx context.Context, teamName, poolName string) error {
+	query := `
+		DELETE FROM team_reviewer_pools
+		WHERE team_name = $1 AND pool_name = $2
+	`
+	result, err := rpr.db.Exec(ctx, query, teamName, poolName)
+	if err != nil {
+		return fmt.Errorf("не удалось отключить пул %v от команды %v: %v", poolName, teamName, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("пул не подключен к команде")
+	}
+	return nil
+}
+
+// GetTeamPools возвращает пулы, подключенные к команде, упорядоченные по имени
+func (rpr *reviewerPoolRepo) GetTeamPools(ctx context.Context, teamName string) ([]models.TeamPool, error) {
+	var teamPools []models.TeamPool
+
+	query := `
+		SELECT team_name, pool_name, mode
+		FROM team_reviewer_pools
+		WHERE team_name = $1
+		ORDER BY pool_name
+	`
+	rows, err := rpr.db.Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пулы команды %v: %v", teamName, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var teamPool models.TeamPool
+		err := rows.Scan(&teamPool.TeamName, &teamPool.PoolName, &teamPool.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка скана строки: %v", err)
+		}
+		teamPools = append(teamPools, teamPool)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка скана строк: %v", err)
+	}
+	return teamPools, nil
+}
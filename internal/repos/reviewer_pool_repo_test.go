@@ -0,0 +1,229 @@
+package repos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewerPoolRepo_SavePool(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewerPoolRepo(db)
+
+	ctx := context.Background()
+	userIDs := []string{"userid1", "userid2"}
+
+	t.Run("состав пула заменяется целиком", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO reviewer_pools \(name\) VALUES \(\$1\) ON CONFLICT DO NOTHING`).
+			WithArgs("security-champions").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`DELETE FROM reviewer_pool_members WHERE pool_name = \$1 AND NOT \(user_id = ANY\(\$2\)\)`).
+			WithArgs("security-champions", userIDs).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec(`INSERT INTO reviewer_pool_members \(pool_name, user_id\) SELECT \$1, unnest\(\$2::text\[\]\) ON CONFLICT DO NOTHING`).
+			WithArgs("security-champions", userIDs).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		err := repo.SavePool(ctx, "security-champions", userIDs)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при добавлении участников откатывает транзакцию", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO reviewer_pools`).
+			WithArgs("security-champions").
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+		mock.ExpectExec(`DELETE FROM reviewer_pool_members`).
+			WithArgs("security-champions", userIDs).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(`INSERT INTO reviewer_pool_members`).
+			WithArgs("security-champions", userIDs).
+			WillReturnError(errors.New("ошибка базы данных"))
+		mock.ExpectRollback()
+
+		err := repo.SavePool(ctx, "security-champions", userIDs)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось добавить участников пула")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReviewerPoolRepo_GetPool(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewerPoolRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT rp.name, u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active FROM reviewer_pools rp LEFT JOIN reviewer_pool_members pm ON pm.pool_name = rp.name LEFT JOIN users u ON u.id = pm.user_id WHERE rp.name = \$1`
+	columns := []string{"name", "id", "username", "team_name", "is_active"}
+
+	t.Run("пул с участниками из разных команд", func(t *testing.T) {
+		userID1, userID2 := "userid1", "userid2"
+		alice, bob := "alice", "bob"
+		backend, frontend := "backend", "frontend"
+		active := true
+		mock.ExpectQuery(query).
+			WithArgs("platform-guild").
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("platform-guild", &userID1, &alice, &backend, &active).
+				AddRow("platform-guild", &userID2, &bob, &frontend, &active))
+
+		pool, err := repo.GetPool(ctx, "platform-guild")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ReviewerPool{
+			Name: "platform-guild",
+			Members: []models.User{
+				{ID: "userid1", Username: "alice", TeamName: "backend", IsActive: true},
+				{ID: "userid2", Username: "bob", TeamName: "frontend", IsActive: true},
+			},
+		}, pool)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пустой пул", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("platform-guild").
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("platform-guild", nil, nil, nil, nil))
+
+		pool, err := repo.GetPool(ctx, "platform-guild")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ReviewerPool{Name: "platform-guild", Members: []models.User{}}, pool)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пул не найден", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("unknown").
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		pool, err := repo.GetPool(ctx, "unknown")
+
+		assert.Error(t, err)
+		assert.Equal(t, "пул не найден", err.Error())
+		assert.Nil(t, pool)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReviewerPoolRepo_DeletePool(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewerPoolRepo(db)
+
+	ctx := context.Background()
+	query := `DELETE FROM reviewer_pools WHERE name = \$1`
+
+	t.Run("успешное удаление пула", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("platform-guild").
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		err := repo.DeletePool(ctx, "platform-guild")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пул не найден", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("unknown").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err := repo.DeletePool(ctx, "unknown")
+
+		assert.Error(t, err)
+		assert.Equal(t, "пул не найден", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReviewerPoolRepo_SetTeamPool(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewerPoolRepo(db)
+
+	ctx := context.Background()
+
+	t.Run("подключение пула меняет режим уже подключенного", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_reviewer_pools \(team_name, pool_name, mode\) VALUES \(\$1, \$2, \$3\) ON CONFLICT \(team_name, pool_name\) DO UPDATE SET mode = EXCLUDED.mode`).
+			WithArgs("payments", "security-champions", models.PoolFallback).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.SetTeamPool(ctx, &models.TeamPool{
+			TeamName: "payments",
+			PoolName: "security-champions",
+			Mode:     models.PoolFallback,
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReviewerPoolRepo_GetTeamPools(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewReviewerPoolRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT team_name, pool_name, mode FROM team_reviewer_pools WHERE team_name = \$1 ORDER BY pool_name`
+
+	t.Run("успешное получение пулов команды", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("payments").
+			WillReturnRows(pgxmock.NewRows([]string{"team_name", "pool_name", "mode"}).
+				AddRow("payments", "platform-guild", models.PoolSupplementary).
+				AddRow("payments", "security-champions", models.PoolFallback))
+
+		teamPools, err := repo.GetTeamPools(ctx, "payments")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TeamPool{
+			{TeamName: "payments", PoolName: "platform-guild", Mode: models.PoolSupplementary},
+			{TeamName: "payments", PoolName: "security-champions", Mode: models.PoolFallback},
+		}, teamPools)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("payments").
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		teamPools, err := repo.GetTeamPools(ctx, "payments")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось получить пулы команды")
+		assert.Nil(t, teamPools)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,57 @@
+package repos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+// scimAttr - где в бд лежит значение атрибута SCIM. У многозначного атрибута from задает
+// подзапрос, каждая строка которого дает одно значение column
+type scimAttr struct {
+	column string
+	from   string
+}
+
+// scimWhere переводит условия фильтра SCIM в условие WHERE, значения добавляются к args.
+// Сравнение без учета регистра. Многозначный атрибут подходит, если подходит любое его значение,
+// для ne - если ни одно не равно. Неизвестный атрибут ведет себя как атрибут без значений
+func scimWhere(conditions []models.SCIMCondition, attrs map[string]scimAttr, args []any) (string, []any) {
+	where := []string{"TRUE"}
+	for _, cond := range conditions {
+		attr, ok := attrs[cond.Attr]
+		if !ok {
+			if cond.Op != "ne" {
+				where = append(where, "FALSE")
+			}
+			continue
+		}
+
+		var pred string
+		switch cond.Op {
+		case "pr":
+			pred = attr.column + " <> ''"
+		case "co":
+			args = append(args, likeEscaper.Replace(cond.Value))
+			pred = fmt.Sprintf("%s ILIKE '%%' || $%d || '%%'", attr.column, len(args))
+		case "sw":
+			args = append(args, likeEscaper.Replace(cond.Value))
+			pred = fmt.Sprintf("%s ILIKE $%d || '%%'", attr.column, len(args))
+		case "ew":
+			args = append(args, likeEscaper.Replace(cond.Value))
+			pred = fmt.Sprintf("%s ILIKE '%%' || $%d", attr.column, len(args))
+		default:
+			args = append(args, cond.Value)
+			pred = fmt.Sprintf("lower(%s) = lower($%d)", attr.column, len(args))
+		}
+		if attr.from != "" {
+			pred = "EXISTS (SELECT 1 " + attr.from + " AND " + pred + ")"
+		}
+		if cond.Op == "ne" {
+			pred = "NOT (" + pred + ")"
+		}
+		where = append(where, pred)
+	}
+	return strings.Join(where, " AND "), args
+}
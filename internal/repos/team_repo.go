@@ -2,15 +2,28 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/jackc/pgx/v5"
 )
 
 type TeamRepo interface {
 	CreateTeam(ctx context.Context, teamName string) error
 	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	ListSCIMGroups(ctx context.Context, query models.SCIMQuery) ([]models.Team, int, error)
 	IsTeamExists(ctx context.Context, teamName string) (*bool, error)
+	GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	UpsertTeamSettings(ctx context.Context, settings *models.TeamSettings) error
+	GetLastRotatedReviewer(ctx context.Context, teamName string) (string, error)
+	SetLastRotatedReviewer(ctx context.Context, teamName, userID string) error
+	SetParentTeam(ctx context.Context, teamName, parentTeam string) error
+	GetTeamAncestors(ctx context.Context, teamName string) ([]string, error)
+	GetChildTeams(ctx context.Context, parentTeams []string) (map[string][]string, error)
+	RenameTeam(ctx context.Context, teamName, newTeamName string) error
+	DeleteTeam(ctx context.Context, teamName string, moves []models.Reassignment) ([]models.User, error)
+	SyncTeam(ctx context.Context, teamName string, upserts []models.User, deactivateIDs []string, moves []models.Reassignment) error
 }
 
 type teamRepo struct {
@@ -36,13 +49,16 @@ func (tr *teamRepo) CreateTeam(ctx context.Context, teamName string) error {
 	return nil
 }
 
+// GetTeam возвращает команду со всеми участниками, включая тех, для кого она дополнительная
 func (tr *teamRepo) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
 	var team models.Team
 
 	query := `
-		SELECT id, username, is_active
-		FROM users
-		WHERE team_name = $1
+		SELECT u.id, u.username, u.is_active, u.skills, tm.is_primary
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_name = $1
+		ORDER BY u.id
 	`
 	rows, err := tr.db.Query(ctx, query, teamName)
 	if err != nil {
@@ -52,7 +68,7 @@ func (tr *teamRepo) GetTeam(ctx context.Context, teamName string) (*models.Team,
 	defer rows.Close()
 	for rows.Next() {
 		var member models.TeamMember
-		err := rows.Scan(&member.UserID, &member.Username, &member.IsActive)
+		err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Skills, &member.IsPrimary)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка скана строки участника: %v", err)
 		}
@@ -67,6 +83,77 @@ func (tr *teamRepo) GetTeam(ctx context.Context, teamName string) (*models.Team,
 	return &team, nil
 }
 
+var scimGroupAttrs = map[string]scimAttr{
+	"id":            {column: "t.name"},
+	"displayname":   {column: "t.name"},
+	"members":       {column: "tm.user_id", from: "FROM team_members tm WHERE tm.team_name = t.name"},
+	"members.value": {column: "tm.user_id", from: "FROM team_members tm WHERE tm.team_name = t.name"},
+}
+
+// ListSCIMGroups возвращает страницу команд, подходящих под фильтр SCIM, с участниками, упорядоченную
+// по имени, и общее число подходящих команд
+func (tr *teamRepo) ListSCIMGroups(ctx context.Context, query models.SCIMQuery) ([]models.Team, int, error) {
+	var teams []models.Team
+
+	where, args := scimWhere(query.Conditions, scimGroupAttrs, nil)
+	var total int
+	err := tr.db.QueryRow(ctx, "SELECT COUNT(*) FROM teams t WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось посчитать команды: %v", err)
+	}
+
+	page := fmt.Sprintf(`
+		WITH page AS (
+			SELECT t.name
+			FROM teams t
+			WHERE %s
+			ORDER BY t.name
+			OFFSET $%d LIMIT $%d
+		)
+		SELECT page.name, u.id, u.username, u.is_active, u.skills, tm.is_primary
+		FROM page
+		LEFT JOIN team_members tm ON tm.team_name = page.name
+		LEFT JOIN users u ON u.id = tm.user_id
+		ORDER BY page.name, u.id
+	`, where, len(args)+1, len(args)+2)
+	rows, err := tr.db.Query(ctx, page, append(args, query.Offset, query.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось получить список команд: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var userID, username *string
+		var isActive, isPrimary *bool
+		var skills []string
+		err := rows.Scan(&name, &userID, &username, &isActive, &skills, &isPrimary)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка скана строки команды: %v", err)
+		}
+
+		if len(teams) == 0 || teams[len(teams)-1].Name != name {
+			teams = append(teams, models.Team{Name: name, Members: []models.TeamMember{}})
+		}
+		// у команды без участников LEFT JOIN дает одну строку с NULL
+		if userID != nil {
+			team := &teams[len(teams)-1]
+			team.Members = append(team.Members, models.TeamMember{
+				UserID:    *userID,
+				Username:  *username,
+				IsActive:  *isActive,
+				Skills:    skills,
+				IsPrimary: *isPrimary,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка скана строк: %v", err)
+	}
+	return teams, total, nil
+}
+
 func (tr *teamRepo) IsTeamExists(ctx context.Context, teamName string) (*bool, error) {
 	var exists bool
 	query := `
@@ -82,3 +169,285 @@ func (tr *teamRepo) IsTeamExists(ctx context.Context, teamName string) (*bool, e
 
 	return &exists, nil
 }
+
+func (tr *teamRepo) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	settings := models.TeamSettings{TeamName: teamName}
+	query := `
+		SELECT min_reviewers, max_reviewers, reviewer_strategy, max_open_reviews, required_approvals,
+			review_sla_hours, sla_business_hours_only, business_hours_start, business_hours_end, timezone,
+			reassign_on_deactivate
+		FROM team_settings
+		WHERE team_name = $1
+	`
+	row := tr.db.QueryRow(ctx, query, teamName)
+	err := row.Scan(&settings.MinReviewers, &settings.MaxReviewers, &settings.ReviewerStrategy, &settings.MaxOpenReviews,
+		&settings.RequiredApprovals, &settings.ReviewSLAHours, &settings.SLABusinessHoursOnly,
+		&settings.BusinessHoursStart, &settings.BusinessHoursEnd, &settings.Timezone, &settings.ReassignOnDeactivate)
+	if err == pgx.ErrNoRows {
+		return models.DefaultTeamSettings(teamName), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить настройки команды %v: %v", teamName, err)
+	}
+	return &settings, nil
+}
+
+func (tr *teamRepo) UpsertTeamSettings(ctx context.Context, settings *models.TeamSettings) error {
+	query := `
+		INSERT INTO team_settings (team_name, min_reviewers, max_reviewers, reviewer_strategy, max_open_reviews, required_approvals,
+			review_sla_hours, sla_business_hours_only, business_hours_start, business_hours_end, timezone, reassign_on_deactivate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (team_name)
+		DO UPDATE SET
+			min_reviewers = EXCLUDED.min_reviewers,
+			max_reviewers = EXCLUDED.max_reviewers,
+			reviewer_strategy = EXCLUDED.reviewer_strategy,
+			max_open_reviews = EXCLUDED.max_open_reviews,
+			required_approvals = EXCLUDED.required_approvals,
+			review_sla_hours = EXCLUDED.review_sla_hours,
+			sla_business_hours_only = EXCLUDED.sla_business_hours_only,
+			business_hours_start = EXCLUDED.business_hours_start,
+			business_hours_end = EXCLUDED.business_hours_end,
+			timezone = EXCLUDED.timezone,
+			reassign_on_deactivate = EXCLUDED.reassign_on_deactivate
+	`
+	_, err := tr.db.Exec(ctx, query,
+		settings.TeamName, settings.MinReviewers, settings.MaxReviewers, settings.ReviewerStrategy,
+		settings.MaxOpenReviews, settings.RequiredApprovals, settings.ReviewSLAHours, settings.SLABusinessHoursOnly,
+		settings.BusinessHoursStart, settings.BusinessHoursEnd, settings.Timezone, settings.ReassignOnDeactivate)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить настройки команды %v: %v", settings.TeamName, err)
+	}
+	return nil
+}
+
+func (tr *teamRepo) GetLastRotatedReviewer(ctx context.Context, teamName string) (string, error) {
+	var userID string
+	query := `
+		SELECT last_reviewer_id
+		FROM team_rotation
+		WHERE team_name = $1
+	`
+	err := tr.db.QueryRow(ctx, query, teamName).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("не удалось получить позицию ротации команды %v: %v", teamName, err)
+	}
+	return userID, nil
+}
+
+func (tr *teamRepo) SetLastRotatedReviewer(ctx context.Context, teamName, userID string) error {
+	query := `
+		INSERT INTO team_rotation (team_name, last_reviewer_id)
+		VALUES ($1, $2)
+		ON CONFLICT (team_name)
+		DO UPDATE SET last_reviewer_id = EXCLUDED.last_reviewer_id
+	`
+	_, err := tr.db.Exec(ctx, query, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить позицию ротации команды %v: %v", teamName, err)
+	}
+	return nil
+}
+
+// SetParentTeam задает родительскую команду, пустой parentTeam делает команду корневой.
+// Отсутствие циклов проверяет сервис
+func (tr *teamRepo) SetParentTeam(ctx context.Context, teamName, parentTeam string) error {
+	query := `
+		UPDATE teams
+		SET parent_team = NULLIF($2, '')
+		WHERE name = $1
+	`
+	result, err := tr.db.Exec(ctx, query, teamName, parentTeam)
+	if err != nil {
+		return fmt.Errorf("не удалось задать родительскую команду %v: %v", teamName, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("команда не найдена")
+	}
+	return nil
+}
+
+// GetTeamAncestors возвращает цепочку родительских команд, начиная с ближайшей.
+// Если в бд все же оказался цикл, обход останавливается на повторе
+func (tr *teamRepo) GetTeamAncestors(ctx context.Context, teamName string) ([]string, error) {
+	var ancestors []string
+
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT t.parent_team AS name, 1 AS depth, ARRAY[t.name] AS path
+			FROM teams t
+			WHERE t.name = $1 AND t.parent_team IS NOT NULL
+			UNION ALL
+			SELECT t.parent_team, a.depth + 1, a.path || t.name
+			FROM ancestors a
+			JOIN teams t ON t.name = a.name
+			WHERE t.parent_team IS NOT NULL AND t.parent_team <> ALL(a.path || t.name)
+		)
+		SELECT name FROM ancestors ORDER BY depth
+	`
+	rows, err := tr.db.Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить родительские команды %v: %v", teamName, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка скана строки команды: %v", err)
+		}
+		ancestors = append(ancestors, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка скана строк: %v", err)
+	}
+	return ancestors, nil
+}
+
+// GetChildTeams возвращает дочерние команды каждой из parentTeams, упорядоченные по имени
+func (tr *teamRepo) GetChildTeams(ctx context.Context, parentTeams []string) (map[string][]string, error) {
+	children := make(map[string][]string)
+
+	query := `
+		SELECT parent_team, name
+		FROM teams
+		WHERE parent_team = ANY($1)
+		ORDER BY parent_team, name
+	`
+	rows, err := tr.db.Query(ctx, query, parentTeams)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить дочерние команды: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var parent, name string
+		if err := rows.Scan(&parent, &name); err != nil {
+			return nil, fmt.Errorf("ошибка скана строки команды: %v", err)
+		}
+		children[parent] = append(children[parent], name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка скана строк: %v", err)
+	}
+	return children, nil
+}
+
+// RenameTeam переименовывает команду. Участники, настройки и ротация переезжают по ON UPDATE CASCADE,
+// CODEOWNERS команды переносится в той же транзакции
+func (tr *teamRepo) RenameTeam(ctx context.Context, teamName, newTeamName string) error {
+	txFunc := func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `UPDATE teams SET name = $1 WHERE name = $2`, newTeamName, teamName)
+		if err != nil {
+			return fmt.Errorf("не удалось переименовать команду %v: %v", teamName, err)
+		}
+		if result.RowsAffected() == 0 {
+			return errors.New("команда не найдена")
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE codeowners SET name = $1 WHERE scope = 'TEAM' AND name = $2`, newTeamName, teamName)
+		if err != nil {
+			return fmt.Errorf("не удалось перенести CODEOWNERS команды %v: %v", teamName, err)
+		}
+		return nil
+	}
+	return tr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+}
+
+// DeleteTeam удаляет команду вместе с настройками и CODEOWNERS и в той же транзакции передает ревью
+// ее участников. Участники теряют только членство в ней: для кого она основная, остаются без основной
+// команды, остальные сохраняют прежнюю. Возвращает бывших участников
+func (tr *teamRepo) DeleteTeam(ctx context.Context, teamName string, moves []models.Reassignment) ([]models.User, error) {
+	var users []models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE users
+			SET team_name = NULL
+			WHERE team_name = $1
+			RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		`
+		primary, err := updateUsers(ctx, tx, "исключить участников команды", query, teamName)
+		if err != nil {
+			return err
+		}
+
+		query = `
+			DELETE FROM team_members tm
+			USING users u
+			WHERE u.id = tm.user_id AND tm.team_name = $1
+			RETURNING u.id, u.username, COALESCE(u.team_name, ''), u.is_active, u.skills, u.max_open_reviews
+		`
+		secondary, err := updateUsers(ctx, tx, "исключить дополнительных участников команды", query, teamName)
+		if err != nil {
+			return err
+		}
+		users = append(primary, secondary...)
+
+		if err := applyReassignments(ctx, tx, moves); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM codeowners WHERE scope = 'TEAM' AND name = $1`, teamName)
+		if err != nil {
+			return fmt.Errorf("не удалось удалить CODEOWNERS команды %v: %v", teamName, err)
+		}
+
+		deleted, err := tx.Exec(ctx, `DELETE FROM teams WHERE name = $1`, teamName)
+		if err != nil {
+			return fmt.Errorf("не удалось удалить команду %v: %v", teamName, err)
+		}
+		if deleted.RowsAffected() == 0 {
+			return errors.New("команда не найдена")
+		}
+		return nil
+	}
+	err := tr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SyncTeam одной транзакцией приводит состав команды к желаемому: создает команду, если ее нет,
+// записывает новых и изменившихся участников, деактивирует выбывших и передает их ревью
+func (tr *teamRepo) SyncTeam(ctx context.Context, teamName string, upserts []models.User, deactivateIDs []string, moves []models.Reassignment) error {
+	txFunc := func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO teams (name) VALUES ($1) ON CONFLICT DO NOTHING`, teamName)
+		if err != nil {
+			return fmt.Errorf("не удалось создать команду: %v", err)
+		}
+
+		query := `
+			INSERT INTO users (id, username, team_name, is_active, skills)
+			VALUES ($1, $2, $3, true, $4)
+			ON CONFLICT (id)
+			DO UPDATE SET
+				username = EXCLUDED.username,
+				team_name = EXCLUDED.team_name,
+				is_active = true,
+				skills = EXCLUDED.skills
+		`
+		for _, user := range upserts {
+			_, err := tx.Exec(ctx, query, user.ID, user.Username, teamName, models.NormalizeTags(user.Skills))
+			if err != nil {
+				return fmt.Errorf("ошибка при создании/обновлении пользователя %v: %v", user.ID, err)
+			}
+		}
+
+		if len(deactivateIDs) > 0 {
+			_, err := tx.Exec(ctx, `UPDATE users SET is_active = false WHERE team_name = $1 AND id = ANY($2)`, teamName, deactivateIDs)
+			if err != nil {
+				return fmt.Errorf("не получилось деактивировать пользователей: %v", err)
+			}
+		}
+
+		return applyReassignments(ctx, tx, moves)
+	}
+	return tr.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -60,22 +61,26 @@ func TestTeamRepo_GetTeam(t *testing.T) {
 	t.Run("успешное получение команды с участниками", func(t *testing.T) {
 		expectedMembers := []models.TeamMember{
 			{
-				UserID:   "user-id1",
-				Username: "user1",
-				IsActive: true,
+				UserID:    "user-id1",
+				Username:  "user1",
+				IsActive:  true,
+				Skills:    []string{"go", "postgres"},
+				IsPrimary: true,
 			},
 			{
-				UserID:   "user-id2",
-				Username: "user2",
-				IsActive: false,
+				UserID:    "user-id2",
+				Username:  "user2",
+				IsActive:  false,
+				Skills:    []string{},
+				IsPrimary: false,
 			},
 		}
 
-		rows := pgxmock.NewRows([]string{"id", "username", "is_active"}).
-			AddRow(expectedMembers[0].UserID, expectedMembers[0].Username, expectedMembers[0].IsActive).
-			AddRow(expectedMembers[1].UserID, expectedMembers[1].Username, expectedMembers[1].IsActive)
+		rows := pgxmock.NewRows([]string{"id", "username", "is_active", "skills", "is_primary"}).
+			AddRow(expectedMembers[0].UserID, expectedMembers[0].Username, expectedMembers[0].IsActive, expectedMembers[0].Skills, expectedMembers[0].IsPrimary).
+			AddRow(expectedMembers[1].UserID, expectedMembers[1].Username, expectedMembers[1].IsActive, expectedMembers[1].Skills, expectedMembers[1].IsPrimary)
 
-		mock.ExpectQuery(`SELECT id, username, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, u.is_active, u.skills, tm.is_primary FROM team_members tm JOIN users u ON u.id = tm.user_id WHERE tm.team_name = \$1 ORDER BY u.id`).
 			WithArgs(teamName).
 			WillReturnRows(rows)
 
@@ -88,7 +93,7 @@ func TestTeamRepo_GetTeam(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, username, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, u.is_active, u.skills, tm.is_primary FROM team_members tm JOIN users u ON u.id = tm.user_id WHERE tm.team_name = \$1 ORDER BY u.id`).
 			WithArgs(teamName).
 			WillReturnError(errors.New("ошибка базы данных"))
 
@@ -104,7 +109,7 @@ func TestTeamRepo_GetTeam(t *testing.T) {
 		rows := pgxmock.NewRows([]string{"id", "username", "is_active"}).
 			AddRow("invalid-id", "user1", true)
 
-		mock.ExpectQuery(`SELECT id, username, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, u.is_active, u.skills, tm.is_primary FROM team_members tm JOIN users u ON u.id = tm.user_id WHERE tm.team_name = \$1 ORDER BY u.id`).
 			WithArgs(teamName).
 			WillReturnRows(rows)
 
@@ -171,3 +176,508 @@ func TestTeamRepo_IsTeamExists(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestTeamRepo_ListSCIMGroups(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	pageQuery := `WITH page AS \( SELECT t.name FROM teams t WHERE TRUE ORDER BY t.name OFFSET \$1 LIMIT \$2 \) SELECT page.name, u.id, u.username, u.is_active, u.skills, tm.is_primary FROM page LEFT JOIN team_members tm ON tm.team_name = page.name LEFT JOIN users u ON u.id = tm.user_id ORDER BY page.name, u.id`
+
+	t.Run("участники группируются по командам, пустая команда без участников", func(t *testing.T) {
+		userID1, userID2, alice, bob := "userid1", "userid2", "alice", "bob"
+		active, inactive := true, false
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM teams t WHERE TRUE$`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(pageQuery).
+			WithArgs(0, 2).
+			WillReturnRows(pgxmock.NewRows([]string{"name", "id", "username", "is_active", "skills", "is_primary"}).
+				AddRow("backend", &userID1, &alice, &active, []string{"go"}, &active).
+				AddRow("backend", &userID2, &bob, &inactive, []string{}, &inactive).
+				AddRow("empty", (*string)(nil), (*string)(nil), (*bool)(nil), []string(nil), (*bool)(nil)))
+
+		teams, total, err := repo.ListSCIMGroups(ctx, models.SCIMQuery{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []models.Team{
+			{Name: "backend", Members: []models.TeamMember{
+				{UserID: "userid1", Username: "alice", IsActive: true, Skills: []string{"go"}, IsPrimary: true},
+				{UserID: "userid2", Username: "bob", IsActive: false, Skills: []string{}, IsPrimary: false},
+			}},
+			{Name: "empty", Members: []models.TeamMember{}},
+		}, teams)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("фильтр по участнику и имени передается в запрос", func(t *testing.T) {
+		query := models.SCIMQuery{
+			Conditions: []models.SCIMCondition{
+				{Attr: "members.value", Op: "eq", Value: "userid1"},
+				{Attr: "displayname", Op: "sw", Value: "back_"},
+			},
+			Offset: 10,
+			Limit:  5,
+		}
+		where := `WHERE TRUE AND EXISTS \(SELECT 1 FROM team_members tm WHERE tm.team_name = t.name AND lower\(tm.user_id\) = lower\(\$1\)\) AND t.name ILIKE \$2 \|\| '%'`
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM teams t `+where).
+			WithArgs("userid1", `back\_`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(where+` ORDER BY t.name OFFSET \$3 LIMIT \$4`).
+			WithArgs("userid1", `back\_`, 10, 5).
+			WillReturnRows(pgxmock.NewRows([]string{"name", "id", "username", "is_active", "skills", "is_primary"}))
+
+		teams, total, err := repo.ListSCIMGroups(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, teams)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при подсчете команд", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM teams t`).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		teams, total, err := repo.ListSCIMGroups(ctx, models.SCIMQuery{Limit: 2})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось посчитать команды")
+		assert.Nil(t, teams)
+		assert.Zero(t, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_GetTeamSettings(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	teamName := "testteam"
+	query := `SELECT min_reviewers, max_reviewers, reviewer_strategy, max_open_reviews, required_approvals, review_sla_hours, sla_business_hours_only, business_hours_start, business_hours_end, timezone, reassign_on_deactivate FROM team_settings WHERE team_name = \$1`
+
+	t.Run("настройки заданы", func(t *testing.T) {
+		expected := &models.TeamSettings{
+			TeamName:             teamName,
+			MinReviewers:         1,
+			MaxReviewers:         3,
+			ReviewerStrategy:     models.StrategyRoundRobin,
+			MaxOpenReviews:       4,
+			RequiredApprovals:    2,
+			ReviewSLAHours:       8,
+			SLABusinessHoursOnly: true,
+			BusinessHoursStart:   10,
+			BusinessHoursEnd:     19,
+			Timezone:             "Europe/Moscow",
+			ReassignOnDeactivate: true,
+		}
+
+		mock.ExpectQuery(query).
+			WithArgs(teamName).
+			WillReturnRows(pgxmock.NewRows([]string{"min_reviewers", "max_reviewers", "reviewer_strategy", "max_open_reviews", "required_approvals",
+				"review_sla_hours", "sla_business_hours_only", "business_hours_start", "business_hours_end", "timezone",
+				"reassign_on_deactivate"}).
+				AddRow(expected.MinReviewers, expected.MaxReviewers, expected.ReviewerStrategy, expected.MaxOpenReviews, expected.RequiredApprovals,
+					expected.ReviewSLAHours, expected.SLABusinessHoursOnly, expected.BusinessHoursStart, expected.BusinessHoursEnd, expected.Timezone,
+					expected.ReassignOnDeactivate))
+
+		settings, err := repo.GetTeamSettings(ctx, teamName)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, settings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("настроек нет - значения по умолчанию", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(teamName).
+			WillReturnError(pgx.ErrNoRows)
+
+		settings, err := repo.GetTeamSettings(ctx, teamName)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.DefaultTeamSettings(teamName), settings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(teamName).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		settings, err := repo.GetTeamSettings(ctx, teamName)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось получить настройки команды")
+		assert.Nil(t, settings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_UpsertTeamSettings(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	settings := &models.TeamSettings{
+		TeamName:         "infra",
+		MinReviewers:     2,
+		MaxReviewers:     3,
+		ReviewerStrategy: models.StrategyLeastLoaded,
+	}
+
+	t.Run("успешное сохранение настроек", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_settings \(team_name, min_reviewers, max_reviewers, reviewer_strategy, max_open_reviews, required_approvals, review_sla_hours, sla_business_hours_only, business_hours_start, business_hours_end, timezone, reassign_on_deactivate\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9, \$10, \$11, \$12\) ON CONFLICT \(team_name\) DO UPDATE`).
+			WithArgs(settings.TeamName, settings.MinReviewers, settings.MaxReviewers, settings.ReviewerStrategy, settings.MaxOpenReviews, settings.RequiredApprovals,
+				settings.ReviewSLAHours, settings.SLABusinessHoursOnly, settings.BusinessHoursStart, settings.BusinessHoursEnd, settings.Timezone,
+				settings.ReassignOnDeactivate).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.UpsertTeamSettings(ctx, settings)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_settings`).
+			WithArgs(settings.TeamName, settings.MinReviewers, settings.MaxReviewers, settings.ReviewerStrategy, settings.MaxOpenReviews, settings.RequiredApprovals,
+				settings.ReviewSLAHours, settings.SLABusinessHoursOnly, settings.BusinessHoursStart, settings.BusinessHoursEnd, settings.Timezone,
+				settings.ReassignOnDeactivate).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		err := repo.UpsertTeamSettings(ctx, settings)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось сохранить настройки команды")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_SetLastRotatedReviewer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	teamName := "testteam"
+
+	t.Run("успешное сохранение позиции ротации", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_rotation \(team_name, last_reviewer_id\) VALUES \(\$1, \$2\) ON CONFLICT \(team_name\) DO UPDATE SET last_reviewer_id = EXCLUDED\.last_reviewer_id`).
+			WithArgs(teamName, "userid2").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.SetLastRotatedReviewer(ctx, teamName, "userid2")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_rotation`).
+			WithArgs(teamName, "userid2").
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		err := repo.SetLastRotatedReviewer(ctx, teamName, "userid2")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось сохранить позицию ротации команды")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_SetParentTeam(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	query := `UPDATE teams SET parent_team = NULLIF\(\$2, ''\) WHERE name = \$1`
+
+	t.Run("успешное изменение родителя", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("payments", "backend").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err := repo.SetParentTeam(ctx, "payments", "backend")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("команда не найдена", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("payments", "").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err := repo.SetParentTeam(ctx, "payments", "")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "команда не найдена")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_GetTeamAncestors(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	query := `WITH RECURSIVE ancestors AS \(.+\) SELECT name FROM ancestors ORDER BY depth`
+
+	t.Run("цепочка от ближайшего предка", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("payments").
+			WillReturnRows(pgxmock.NewRows([]string{"name"}).
+				AddRow("backend").
+				AddRow("engineering"))
+
+		ancestors, err := repo.GetTeamAncestors(ctx, "payments")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"backend", "engineering"}, ancestors)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("payments").
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		ancestors, err := repo.GetTeamAncestors(ctx, "payments")
+
+		assert.Error(t, err)
+		assert.Nil(t, ancestors)
+		assert.Contains(t, err.Error(), "не удалось получить родительские команды")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_GetChildTeams(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+
+	t.Run("дочерние команды сгруппированы по родителю", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT parent_team, name FROM teams WHERE parent_team = ANY\(\$1\) ORDER BY parent_team, name`).
+			WithArgs([]string{"backend", "engineering"}).
+			WillReturnRows(pgxmock.NewRows([]string{"parent_team", "name"}).
+				AddRow("backend", "payments").
+				AddRow("backend", "search").
+				AddRow("engineering", "backend"))
+
+		children, err := repo.GetChildTeams(ctx, []string{"backend", "engineering"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"backend":     {"payments", "search"},
+			"engineering": {"backend"},
+		}, children)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_RenameTeam(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+
+	t.Run("успешное переименование вместе с CODEOWNERS", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE teams SET name = \$1 WHERE name = \$2`).
+			WithArgs("platform", "backend").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE codeowners SET name = \$1 WHERE scope = 'TEAM' AND name = \$2`).
+			WithArgs("platform", "backend").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		err := repo.RenameTeam(ctx, "backend", "platform")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("команда не найдена", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE teams SET name = \$1 WHERE name = \$2`).
+			WithArgs("platform", "backend").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		err := repo.RenameTeam(ctx, "backend", "platform")
+
+		assert.Error(t, err)
+		assert.Equal(t, "команда не найдена", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_DeleteTeam(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	userColumns := []string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}
+	primaryQuery := `UPDATE users SET team_name = NULL WHERE team_name = \$1 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`
+	secondaryQuery := `DELETE FROM team_members tm USING users u WHERE u.id = tm.user_id AND tm.team_name = \$1 RETURNING`
+
+	t.Run("участники теряют только членство в команде, ревью передаются", func(t *testing.T) {
+		moves := []models.Reassignment{
+			{PRID: "pr-0007", OldReviewerID: "userid1", NewReviewerID: "userid3", Reason: models.ReasonReorg},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(primaryQuery).
+			WithArgs("backend").
+			WillReturnRows(pgxmock.NewRows(userColumns).
+				AddRow("userid1", "alice", "", true, []string{}, 0))
+		mock.ExpectQuery(secondaryQuery).
+			WithArgs("backend").
+			WillReturnRows(pgxmock.NewRows(userColumns).
+				AddRow("userid2", "bob", "platform", true, []string{}, 0))
+		mock.ExpectExec(`UPDATE pr_reviewers r SET reviewer_id = m.new_id`).
+			WithArgs([]string{"pr-0007"}, []string{"userid1"}, []string{"userid3"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments`).
+			WithArgs([]string{"pr-0007"}, []string{"userid1"}, []string{"userid3"}, []string{"REORG"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`DELETE FROM codeowners WHERE scope = 'TEAM' AND name = \$1`).
+			WithArgs("backend").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(`DELETE FROM teams WHERE name = \$1`).
+			WithArgs("backend").
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectCommit()
+
+		users, err := repo.DeleteTeam(ctx, "backend", moves)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{
+			{ID: "userid1", Username: "alice", IsActive: true, Skills: []string{}},
+			{ID: "userid2", Username: "bob", TeamName: "platform", IsActive: true, Skills: []string{}},
+		}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при удалении команды", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(primaryQuery).
+			WithArgs("backend").
+			WillReturnRows(pgxmock.NewRows(userColumns))
+		mock.ExpectQuery(secondaryQuery).
+			WithArgs("backend").
+			WillReturnRows(pgxmock.NewRows(userColumns))
+		mock.ExpectExec(`DELETE FROM codeowners`).
+			WithArgs("backend").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(`DELETE FROM teams WHERE name = \$1`).
+			WithArgs("backend").
+			WillReturnError(errors.New("ошибка базы данных"))
+		mock.ExpectRollback()
+
+		users, err := repo.DeleteTeam(ctx, "backend", nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось удалить команду")
+		assert.Nil(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTeamRepo_SyncTeam(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewTeamRepo(db)
+
+	ctx := context.Background()
+	upsertQuery := `INSERT INTO users \(id, username, team_name, is_active, skills\) VALUES \(\$1, \$2, \$3, true, \$4\) ON CONFLICT \(id\)`
+	upserts := []models.User{{ID: "userid4", Username: "dave", Skills: []string{"Go"}}}
+	moves := []models.Reassignment{
+		{PRID: "pr-0001", OldReviewerID: "userid1", NewReviewerID: "userid4", Reason: models.ReasonReorg},
+	}
+
+	t.Run("состав применяется одной транзакцией", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO teams \(name\) VALUES \(\$1\) ON CONFLICT DO NOTHING`).
+			WithArgs("backend").
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+		mock.ExpectExec(upsertQuery).
+			WithArgs("userid4", "dave", "backend", []string{"go"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`UPDATE users SET is_active = false WHERE team_name = \$1 AND id = ANY\(\$2\)`).
+			WithArgs("backend", []string{"userid1"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`UPDATE pr_reviewers r SET reviewer_id = m.new_id`).
+			WithArgs([]string{"pr-0001"}, []string{"userid1"}, []string{"userid4"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments`).
+			WithArgs([]string{"pr-0001"}, []string{"userid1"}, []string{"userid4"}, []string{"REORG"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := repo.SyncTeam(ctx, "backend", upserts, []string{"userid1"}, moves)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при записи участника откатывает транзакцию", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO teams`).
+			WithArgs("backend").
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+		mock.ExpectExec(upsertQuery).
+			WithArgs("userid4", "dave", "backend", []string{"go"}).
+			WillReturnError(errors.New("ошибка базы данных"))
+		mock.ExpectRollback()
+
+		err := repo.SyncTeam(ctx, "backend", upserts, []string{"userid1"}, moves)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при создании/обновлении пользователя userid4")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,108 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type UnavailabilityRepo interface {
+	CreateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error)
+	GetUnavailabilityByUser(ctx context.Context, userID string, includeExpired bool) ([]models.Unavailability, error)
+	UpdateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error)
+	DeleteUnavailability(ctx context.Context, id int64, userID string) error
+}
+
+type unavailabilityRepo struct {
+	db DBInterface
+}
+
+func NewUnavailabilityRepo(db DBInterface) UnavailabilityRepo {
+	return &unavailabilityRepo{
+		db: db,
+	}
+}
+
+func (uar *unavailabilityRepo) CreateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error) {
+	var created models.Unavailability
+
+	query := `
+		INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, starts_at, ends_at, reason
+	`
+	row := uar.db.QueryRow(ctx, query, period.UserID, period.StartsAt, period.EndsAt, period.Reason)
+	err := row.Scan(&created.ID, &created.UserID, &created.StartsAt, &created.EndsAt, &created.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании периода отсутствия: %v", err)
+	}
+	return &created, nil
+}
+
+func (uar *unavailabilityRepo) GetUnavailabilityByUser(ctx context.Context, userID string, includeExpired bool) ([]models.Unavailability, error) {
+	var periods []models.Unavailability
+
+	query := `
+		SELECT id, user_id, starts_at, ends_at, reason
+		FROM user_unavailability
+		WHERE user_id = $1 AND ($2 OR ends_at > now())
+		ORDER BY starts_at
+	`
+	rows, err := uar.db.Query(ctx, query, userID, includeExpired)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении периодов отсутствия: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var period models.Unavailability
+		err := rows.Scan(&period.ID, &period.UserID, &period.StartsAt, &period.EndsAt, &period.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		periods = append(periods, period)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при скане строк: %v", err)
+	}
+	return periods, nil
+}
+
+func (uar *unavailabilityRepo) UpdateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error) {
+	var updated models.Unavailability
+
+	query := `
+		UPDATE user_unavailability
+		SET starts_at = $1, ends_at = $2, reason = $3
+		WHERE id = $4 AND user_id = $5
+		RETURNING id, user_id, starts_at, ends_at, reason
+	`
+	row := uar.db.QueryRow(ctx, query, period.StartsAt, period.EndsAt, period.Reason, period.ID, period.UserID)
+	err := row.Scan(&updated.ID, &updated.UserID, &updated.StartsAt, &updated.EndsAt, &updated.Reason)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New("период отсутствия не найден")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при обновлении периода отсутствия: %v", err)
+	}
+	return &updated, nil
+}
+
+func (uar *unavailabilityRepo) DeleteUnavailability(ctx context.Context, id int64, userID string) error {
+	query := `
+		DELETE FROM user_unavailability
+		WHERE id = $1 AND user_id = $2
+	`
+	result, err := uar.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении периода отсутствия: %v", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("период отсутствия не найден")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package repos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnavailabilityRepo_CreateUnavailability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUnavailabilityRepo(db)
+
+	ctx := context.Background()
+	period := &models.Unavailability{
+		UserID:   "userid1",
+		StartsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC),
+		Reason:   "отпуск",
+	}
+	query := `INSERT INTO user_unavailability \(user_id, starts_at, ends_at, reason\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id, user_id, starts_at, ends_at, reason`
+
+	t.Run("успешное создание периода отсутствия", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(period.UserID, period.StartsAt, period.EndsAt, period.Reason).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "starts_at", "ends_at", "reason"}).
+				AddRow(int64(1), period.UserID, period.StartsAt, period.EndsAt, period.Reason))
+
+		created, err := repo.CreateUnavailability(ctx, period)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), created.ID)
+		assert.Equal(t, period.EndsAt, created.EndsAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(period.UserID, period.StartsAt, period.EndsAt, period.Reason).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		created, err := repo.CreateUnavailability(ctx, period)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при создании периода отсутствия")
+		assert.Nil(t, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnavailabilityRepo_GetUnavailabilityByUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUnavailabilityRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT id, user_id, starts_at, ends_at, reason FROM user_unavailability WHERE user_id = \$1 AND \(\$2 OR ends_at > now\(\)\) ORDER BY starts_at`
+
+	t.Run("успешное получение периодов", func(t *testing.T) {
+		expected := []models.Unavailability{
+			{
+				ID:       3,
+				UserID:   "userid1",
+				StartsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+				EndsAt:   time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC),
+			},
+		}
+
+		mock.ExpectQuery(query).
+			WithArgs("userid1", false).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "starts_at", "ends_at", "reason"}).
+				AddRow(expected[0].ID, expected[0].UserID, expected[0].StartsAt, expected[0].EndsAt, ""))
+
+		periods, err := repo.GetUnavailabilityByUser(ctx, "userid1", false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, periods)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1", true).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		periods, err := repo.GetUnavailabilityByUser(ctx, "userid1", true)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении периодов отсутствия")
+		assert.Nil(t, periods)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnavailabilityRepo_UpdateUnavailability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUnavailabilityRepo(db)
+
+	ctx := context.Background()
+	period := &models.Unavailability{
+		ID:       3,
+		UserID:   "userid1",
+		StartsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("период не найден", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE user_unavailability SET starts_at = \$1, ends_at = \$2, reason = \$3 WHERE id = \$4 AND user_id = \$5`).
+			WithArgs(period.StartsAt, period.EndsAt, period.Reason, period.ID, period.UserID).
+			WillReturnError(pgx.ErrNoRows)
+
+		updated, err := repo.UpdateUnavailability(ctx, period)
+
+		assert.Error(t, err)
+		assert.Equal(t, "период отсутствия не найден", err.Error())
+		assert.Nil(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnavailabilityRepo_DeleteUnavailability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUnavailabilityRepo(db)
+
+	ctx := context.Background()
+	query := `DELETE FROM user_unavailability WHERE id = \$1 AND user_id = \$2`
+
+	t.Run("успешное удаление", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(3), "userid1").
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		err := repo.DeleteUnavailability(ctx, 3, "userid1")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("период не найден", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(4), "userid1").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err := repo.DeleteUnavailability(ctx, 4, "userid1")
+
+		assert.Error(t, err)
+		assert.Equal(t, "период отсутствия не найден", err.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
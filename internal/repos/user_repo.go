@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -11,11 +13,44 @@ import (
 
 type UserRepo interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, userIDs []string) ([]models.User, error)
+	ListSCIMUsers(ctx context.Context, query models.SCIMQuery) ([]models.UserWithTeams, int, error)
+	ListUsers(ctx context.Context, filter models.UserListFilter) ([]models.User, error)
 	UpsertUser(ctx context.Context, user *models.User) error
 	SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
+	DeactivateUser(ctx context.Context, userID string, moves []models.Reassignment, rotation map[string]string) (*models.User, error)
+	DeactivateUsers(ctx context.Context, teamName string, userIDs []string, moves []models.Reassignment) ([]models.User, error)
+	RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, moves []models.Reassignment) ([]models.User, error)
+	AddTeamMembership(ctx context.Context, teamName, userID string) error
+	GetUserTeams(ctx context.Context, userID string) ([]string, error)
+	FilterTeamMembers(ctx context.Context, teamName string, userIDs []string) ([]string, error)
+	MoveUser(ctx context.Context, userID, fromTeam, toTeam string, moves []models.Reassignment) (*models.User, error)
+	DeleteUser(ctx context.Context, userID string, moves []models.Reassignment, rotation map[string]string) (*models.User, error)
+	GetTeamWorkload(ctx context.Context, teamName string, excludeIDs []string) ([]models.ReviewCandidate, error)
 	GetActiveUsersByTeam(ctx context.Context, teamName string) ([]models.User, error)
+	GetReviewCandidates(ctx context.Context, filter models.CandidateFilter) ([]models.ReviewCandidate, error)
+	GetCodeOwnerCandidates(ctx context.Context, handles, teamNames, excludeIDs []string) ([]models.ReviewCandidate, error)
+	GetReviewerAvailability(ctx context.Context, userID string) (*models.ReviewerAvailability, error)
+	SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error)
+	SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews int) (*models.User, error)
 }
 
+// общие условия для запросов кандидатов в ревьюеры: пользователь не в периоде отсутствия
+// и число его OPEN ревью ниже личного лимита, а если он не задан - лимита команды (0 - без лимита)
+const (
+	notUnavailable = `NOT EXISTS (
+				SELECT 1
+				FROM user_unavailability ua
+				WHERE ua.user_id = u.id AND now() >= ua.starts_at AND now() < ua.ends_at
+			)`
+	belowCapacity = `COUNT(p.id) < COALESCE(NULLIF(u.max_open_reviews, 0), NULLIF(ts.max_open_reviews, 0), 2147483647)`
+	// участие в команде проверяется через EXISTS, а не JOIN с team_members: иначе пользователь
+	// из нескольких подходящих команд попал бы в выборку дважды, а его OPEN ревью посчитались бы дважды
+	memberOfTeam  = `EXISTS (SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = $1)`
+	memberOfTeams = `EXISTS (SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = ANY($2))`
+	memberOfPools = `EXISTS (SELECT 1 FROM reviewer_pool_members pm WHERE pm.user_id = u.id AND pm.pool_name = ANY($6))`
+)
+
 type userRepo struct {
 	db DBInterface
 }
@@ -30,13 +65,13 @@ func (ur *userRepo) GetUser(ctx context.Context, userID string) (*models.User, e
 	var user models.User
 
 	query := `
-		SELECT id, username, team_name, is_active
+		SELECT id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
 		FROM users
 		WHERE id = $1
 	`
 	row := ur.db.QueryRow(ctx, query, userID)
 
-	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
 	if err == pgx.ErrNoRows {
 		return nil, errors.New("пользователь не найден")
 	}
@@ -46,18 +81,119 @@ func (ur *userRepo) GetUser(ctx context.Context, userID string) (*models.User, e
 	return &user, nil
 }
 
+// GetUsersByIDs возвращает найденных пользователей, отсутствующие id пропускаются
+func (ur *userRepo) GetUsersByIDs(ctx context.Context, userIDs []string) ([]models.User, error) {
+	query := `
+		SELECT id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		FROM users
+		WHERE id = ANY($1)
+		ORDER BY id
+	`
+	return ur.queryUsers(ctx, query, userIDs)
+}
+
+var scimUserAttrs = map[string]scimAttr{
+	"id":           {column: "u.id"},
+	"externalid":   {column: "u.id"},
+	"username":     {column: "u.username"},
+	"active":       {column: "u.is_active::text"},
+	"groups":       {column: "tm.team_name", from: "FROM team_members tm WHERE tm.user_id = u.id"},
+	"groups.value": {column: "tm.team_name", from: "FROM team_members tm WHERE tm.user_id = u.id"},
+}
+
+// ListSCIMUsers возвращает страницу пользователей, подходящих под фильтр SCIM, включая неактивных
+// и оставшихся без команды, вместе со всеми их командами и общим числом подходящих пользователей
+func (ur *userRepo) ListSCIMUsers(ctx context.Context, query models.SCIMQuery) ([]models.UserWithTeams, int, error) {
+	var users []models.UserWithTeams
+
+	where, args := scimWhere(query.Conditions, scimUserAttrs, nil)
+	var total int
+	err := ur.db.QueryRow(ctx, "SELECT COUNT(*) FROM users u WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось посчитать пользователей: %v", err)
+	}
+
+	page := fmt.Sprintf(`
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active, u.skills, u.max_open_reviews,
+			ARRAY(SELECT tm.team_name FROM team_members tm WHERE tm.user_id = u.id ORDER BY tm.team_name) AS teams
+		FROM users u
+		WHERE %s
+		ORDER BY u.id
+		OFFSET $%d LIMIT $%d
+	`, where, len(args)+1, len(args)+2)
+	rows, err := ur.db.Query(ctx, page, append(args, query.Offset, query.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось получить пользователей: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var user models.UserWithTeams
+		err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews, &user.Teams)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+	return users, total, nil
+}
+
+// ListUsers возвращает до filter.Limit пользователей в порядке id, начиная после filter.AfterID
+func (ur *userRepo) ListUsers(ctx context.Context, filter models.UserListFilter) ([]models.User, error) {
+	query := `
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active, u.skills, u.max_open_reviews
+		FROM users u
+		WHERE ($1::text = '' OR ` + memberOfTeam + `)
+			AND ($2::boolean IS NULL OR u.is_active = $2)
+			AND ($3::text = '' OR u.username ILIKE $3 || '%')
+			AND ($4::text = '' OR u.id > $4)
+		ORDER BY u.id
+		LIMIT $5
+	`
+	return ur.queryUsers(ctx, query, filter.TeamName, filter.IsActive, likeEscaper.Replace(filter.UsernamePrefix), filter.AfterID, filter.Limit)
+}
+
+func (ur *userRepo) queryUsers(ctx context.Context, query string, args ...any) ([]models.User, error) {
+	var users []models.User
+
+	rows, err := ur.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пользователей: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+	return users, nil
+}
+
 func (ur *userRepo) UpsertUser(ctx context.Context, user *models.User) error {
+	skills := models.NormalizeTags(user.Skills)
+
 	query := `
-		INSERT INTO users (id, username, team_name, is_active)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (id, username, team_name, is_active, skills)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
 		ON CONFLICT (id)
 		DO UPDATE SET
 			username = EXCLUDED.username,
 			team_name = EXCLUDED.team_name,
-			is_active = EXCLUDED.is_active
+			is_active = EXCLUDED.is_active,
+			skills = EXCLUDED.skills
 	`
 
-	_, err := ur.db.Exec(ctx, query, user.ID, user.Username, user.TeamName, user.IsActive)
+	_, err := ur.db.Exec(ctx, query, user.ID, user.Username, user.TeamName, user.IsActive, skills)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании/обновлении пользователя: %v", err)
 	}
@@ -71,10 +207,10 @@ func (ur *userRepo) SetUserActive(ctx context.Context, userID string, isActive b
 		UPDATE users
 		SET is_active = $1
 		WHERE id = $2
-		RETURNING id, username, team_name, is_active
+		RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
 	`
 	row := ur.db.QueryRow(ctx, query, isActive, userID)
-	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
 	if err == pgx.ErrNoRows {
 		return nil, errors.New("пользователь не найден")
 	}
@@ -85,13 +221,399 @@ func (ur *userRepo) SetUserActive(ctx context.Context, userID string, isActive b
 	return &user, nil
 }
 
+// DeactivateUser деактивирует пользователя и в той же транзакции передает его ревью новым ревьюерам
+// и сохраняет позиции ротации команд, выбранные при подборе замен
+func (ur *userRepo) DeactivateUser(ctx context.Context, userID string, moves []models.Reassignment, rotation map[string]string) (*models.User, error) {
+	var user models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE users
+			SET is_active = false
+			WHERE id = $1
+			RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		`
+		err := tx.QueryRow(ctx, query, userID).
+			Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+		if err == pgx.ErrNoRows {
+			return errors.New("пользователь не найден")
+		}
+		if err != nil {
+			return fmt.Errorf("не получилось изменить статус пользователя: %v", err)
+		}
+
+		if err := applyReassignments(ctx, tx, moves); err != nil {
+			return err
+		}
+		return applyRotation(ctx, tx, rotation)
+	}
+	err := ur.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteUser переносит OPEN ревью пользователя, сохраняет позиции ротации команд и удаляет его. Оставшиеся
+// назначения удаляются, а в закрытых пулл реквестах и решениях ревью ссылка на него обнуляется внешними ключами
+func (ur *userRepo) DeleteUser(ctx context.Context, userID string, moves []models.Reassignment, rotation map[string]string) (*models.User, error) {
+	var user models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		if err := applyReassignments(ctx, tx, moves); err != nil {
+			return err
+		}
+		if err := applyRotation(ctx, tx, rotation); err != nil {
+			return err
+		}
+
+		query := `
+			DELETE FROM users
+			WHERE id = $1
+			RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		`
+		err := tx.QueryRow(ctx, query, userID).
+			Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+		if err == pgx.ErrNoRows {
+			return errors.New("пользователь не найден")
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось удалить пользователя: %v", err)
+		}
+		return nil
+	}
+	err := ur.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// applyReassignments переносит назначения внутри транзакции одним запросом и записывает их в историю.
+// Если хотя бы одно назначение уже изменилось, возвращает ошибку, чтобы транзакция откатилась
+func applyReassignments(ctx context.Context, tx pgx.Tx, moves []models.Reassignment) error {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, 0, len(moves))
+	oldIDs := make([]string, 0, len(moves))
+	newIDs := make([]string, 0, len(moves))
+	reasons := make([]string, 0, len(moves))
+	sources := make([]string, 0, len(moves))
+	for _, move := range moves {
+		prIDs = append(prIDs, move.PRID)
+		oldIDs = append(oldIDs, move.OldReviewerID)
+		newIDs = append(newIDs, move.NewReviewerID)
+		reasons = append(reasons, string(move.Reason))
+		sources = append(sources, string(move.Source()))
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE pr_reviewers r
+		SET reviewer_id = m.new_id, assigned_via = m.source, assigned_at = now()
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[]) AS m(pr_id, old_id, new_id, source)
+		WHERE r.pr_id = m.pr_id AND r.reviewer_id = m.old_id
+	`, prIDs, oldIDs, newIDs, sources)
+	if err != nil {
+		return fmt.Errorf("ошибка при переназначении ревьюеров: %v", err)
+	}
+	if result.RowsAffected() != int64(len(moves)) {
+		return errors.New("назначения ревьюеров изменились во время переназначения")
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pr_reassignments (pr_id, old_reviewer_id, new_reviewer_id, reason)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[])
+	`, prIDs, oldIDs, newIDs, reasons)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи истории переназначения: %v", err)
+	}
+	return nil
+}
+
+// applyRotation сохраняет внутри транзакции позиции ротации команд, на которых остановился подбор замен
+func applyRotation(ctx context.Context, tx pgx.Tx, rotation map[string]string) error {
+	if len(rotation) == 0 {
+		return nil
+	}
+
+	teams := slices.Sorted(maps.Keys(rotation))
+	reviewers := make([]string, 0, len(teams))
+	for _, team := range teams {
+		reviewers = append(reviewers, rotation[team])
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO team_rotation (team_name, last_reviewer_id)
+		SELECT * FROM unnest($1::text[], $2::text[])
+		ON CONFLICT (team_name)
+		DO UPDATE SET last_reviewer_id = EXCLUDED.last_reviewer_id
+	`, teams, reviewers)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить позиции ротации команд: %v", err)
+	}
+	return nil
+}
+
+// DeactivateUsers деактивирует участников команды, в том числе дополнительных,
+// и в той же транзакции передает их ревью
+func (ur *userRepo) DeactivateUsers(ctx context.Context, teamName string, userIDs []string, moves []models.Reassignment) ([]models.User, error) {
+	var users []models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE users u
+			SET is_active = false
+			WHERE ` + memberOfTeam + ` AND u.id = ANY($2)
+			RETURNING u.id, u.username, COALESCE(u.team_name, ''), u.is_active, u.skills, u.max_open_reviews
+		`
+		var err error
+		users, err = updateUsers(ctx, tx, "деактивировать пользователей", query, teamName, userIDs)
+		if err != nil {
+			return err
+		}
+		return applyReassignments(ctx, tx, moves)
+	}
+	err := ur.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// RemoveTeamMembers исключает пользователей из команды и в той же транзакции передает их ревью.
+// Для кого команда основная, те деактивируются и теряют участие во всех командах, оставаясь в бд
+// без команды. Дополнительные участники только покидают команду и возвращаются с прежней основной
+func (ur *userRepo) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, moves []models.Reassignment) ([]models.User, error) {
+	var users []models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE users
+			SET is_active = false, team_name = NULL
+			WHERE team_name = $1 AND id = ANY($2)
+			RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		`
+		primary, err := updateUsers(ctx, tx, "деактивировать пользователей", query, teamName, userIDs)
+		if err != nil {
+			return err
+		}
+		primaryIDs := make([]string, 0, len(primary))
+		for _, user := range primary {
+			primaryIDs = append(primaryIDs, user.ID)
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM team_members WHERE user_id = ANY($1)`, primaryIDs)
+		if err != nil {
+			return fmt.Errorf("не удалось исключить пользователей из других команд: %v", err)
+		}
+
+		query = `
+			DELETE FROM team_members tm
+			USING users u
+			WHERE u.id = tm.user_id AND tm.team_name = $1 AND tm.user_id = ANY($2)
+			RETURNING u.id, u.username, COALESCE(u.team_name, ''), u.is_active, u.skills, u.max_open_reviews
+		`
+		secondary, err := updateUsers(ctx, tx, "исключить пользователей из команды", query, teamName, userIDs)
+		if err != nil {
+			return err
+		}
+		users = append(primary, secondary...)
+
+		return applyReassignments(ctx, tx, moves)
+	}
+	err := ur.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// updateUsers выполняет в транзакции запрос с RETURNING пользователей, action описывает его в ошибке
+func updateUsers(ctx context.Context, tx pgx.Tx, action, query string, args ...any) ([]models.User, error) {
+	var users []models.User
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("не получилось %s: %v", action, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+	return users, nil
+}
+
+// AddTeamMembership добавляет пользователю дополнительную команду, основная не меняется
+func (ur *userRepo) AddTeamMembership(ctx context.Context, teamName, userID string) error {
+	query := `
+		INSERT INTO team_members (team_name, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := ur.db.Exec(ctx, query, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось добавить пользователя в команду %v: %v", teamName, err)
+	}
+	return nil
+}
+
+// GetUserTeams возвращает все команды пользователя, основную и дополнительные, упорядоченные по имени
+func (ur *userRepo) GetUserTeams(ctx context.Context, userID string) ([]string, error) {
+	var teams []string
+
+	query := `
+		SELECT team_name
+		FROM team_members
+		WHERE user_id = $1
+		ORDER BY team_name
+	`
+	rows, err := ur.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить команды пользователя %v: %v", userID, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования строки: %v", err)
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+	return teams, nil
+}
+
+// FilterTeamMembers возвращает тех из userIDs, кто состоит в команде teamName, основной или дополнительной
+func (ur *userRepo) FilterTeamMembers(ctx context.Context, teamName string, userIDs []string) ([]string, error) {
+	var members []string
+
+	query := `
+		SELECT user_id
+		FROM team_members
+		WHERE team_name = $1 AND user_id = ANY($2)
+		ORDER BY user_id
+	`
+	rows, err := ur.db.Query(ctx, query, teamName, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить участников команды %v: %v", teamName, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования строки: %v", err)
+		}
+		members = append(members, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+	return members, nil
+}
+
+// MoveUser меняет основную команду пользователя с fromTeam на toTeam и в той же транзакции
+// передает его ревью участникам прежней команды. Участие в прежней основной команде прекращается,
+// дополнительное участие в toTeam становится основным
+func (ur *userRepo) MoveUser(ctx context.Context, userID, fromTeam, toTeam string, moves []models.Reassignment) (*models.User, error) {
+	var user models.User
+
+	txFunc := func(tx pgx.Tx) error {
+		query := `
+			UPDATE users
+			SET team_name = $1
+			WHERE id = $2 AND COALESCE(team_name, '') = $3
+			RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+		`
+		err := tx.QueryRow(ctx, query, toTeam, userID, fromTeam).
+			Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+		if err == pgx.ErrNoRows {
+			return errors.New("пользователь не найден в команде")
+		}
+		if err != nil {
+			return fmt.Errorf("не получилось перевести пользователя: %v", err)
+		}
+
+		return applyReassignments(ctx, tx, moves)
+	}
+	err := ur.db.WithinTx(ctx, txFunc, &pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetTeamWorkload возвращает активных и доступных участников команды с их нагрузкой,
+// действующим лимитом OPEN ревью и навыками - для распределения ревью в памяти
+func (ur *userRepo) GetTeamWorkload(ctx context.Context, teamName string, excludeIDs []string) ([]models.ReviewCandidate, error) {
+	var candidates []models.ReviewCandidate
+
+	if excludeIDs == nil {
+		excludeIDs = []string{}
+	}
+
+	query := `
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), COUNT(p.id) AS open_reviews,
+			COALESCE(NULLIF(u.max_open_reviews, 0), ts.max_open_reviews, 0) AS max_open_reviews, u.skills
+		FROM users u
+		LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+		LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+		LEFT JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		WHERE ` + memberOfTeam + ` AND u.is_active AND NOT (u.id = ANY($2))
+			AND ` + notUnavailable + `
+		GROUP BY u.id, u.username, u.team_name, u.max_open_reviews, ts.max_open_reviews, u.skills
+		ORDER BY u.id
+	`
+	rows, err := ur.db.Query(ctx, query, teamName, excludeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении нагрузки команды %v: %v", teamName, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var candidate models.ReviewCandidate
+		err := rows.Scan(&candidate.UserID, &candidate.Username, &candidate.TeamName, &candidate.OpenReviews,
+			&candidate.MaxOpenReviews, &candidate.Skills)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+
+	return candidates, nil
+}
+
 func (ur *userRepo) GetActiveUsersByTeam(ctx context.Context, teamName string) ([]models.User, error) {
 	var activeUsers []models.User
 
 	query := `
-		SELECT id, username, team_name, is_active
-		FROM users
-		WHERE team_name = $1
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active
+		FROM users u
+		WHERE ` + memberOfTeam + ` AND u.is_active
 	`
 	rows, err := ur.db.Query(ctx, query, teamName)
 	if err != nil {
@@ -114,3 +636,175 @@ func (ur *userRepo) GetActiveUsersByTeam(ctx context.Context, teamName string) (
 
 	return activeUsers, nil
 }
+
+// GetReviewCandidates возвращает активных участников команды, включая дополнительных, и участников пулов filter.Pools,
+// отсортированных по числу OPEN ревью, при равной нагрузке порядок случайный, вместе с действующим лимитом ревью.
+// Limit = 0 означает без ограничения, непустой Skills оставляет только тех, у кого есть хотя бы один из навыков.
+// Пользователи в активном периоде отсутствия и достигшие лимита ревью не считаются кандидатами,
+// лимит не учитывается при IgnoreCapacity
+func (ur *userRepo) GetReviewCandidates(ctx context.Context, filter models.CandidateFilter) ([]models.ReviewCandidate, error) {
+	var candidates []models.ReviewCandidate
+
+	exclude := filter.ExcludeIDs
+	if exclude == nil {
+		exclude = []string{}
+	}
+	skills := models.NormalizeTags(filter.Skills)
+	pools := filter.Pools
+	if pools == nil {
+		pools = []string{}
+	}
+
+	query := `
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), COUNT(p.id) AS open_reviews,
+			COALESCE(NULLIF(u.max_open_reviews, 0), ts.max_open_reviews, 0) AS max_open_reviews
+		FROM users u
+		LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+		LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+		LEFT JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		WHERE (` + memberOfTeam + ` OR ` + memberOfPools + `) AND u.is_active AND NOT (u.id = ANY($2))
+			AND (cardinality($4::text[]) = 0 OR u.skills && $4::text[])
+			AND ` + notUnavailable + `
+		GROUP BY u.id, u.username, u.team_name, u.max_open_reviews, ts.max_open_reviews
+		HAVING $5::boolean OR ` + belowCapacity + `
+		ORDER BY open_reviews, random()
+		LIMIT NULLIF($3::int, 0)
+	`
+	rows, err := ur.db.Query(ctx, query, filter.TeamName, exclude, filter.Limit, skills, filter.IgnoreCapacity, pools)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении кандидатов в ревьюеры команды %v: %v", filter.TeamName, err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var candidate models.ReviewCandidate
+		err := rows.Scan(&candidate.UserID, &candidate.Username, &candidate.TeamName, &candidate.OpenReviews,
+			&candidate.MaxOpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+
+	return candidates, nil
+}
+
+// GetReviewerAvailability проверяет пользователя по тем же условиям периода отсутствия и лимита
+// нагрузки, по которым отбираются кандидаты в ревьюеры
+func (ur *userRepo) GetReviewerAvailability(ctx context.Context, userID string) (*models.ReviewerAvailability, error) {
+	var availability models.ReviewerAvailability
+
+	query := `
+		SELECT NOT (` + notUnavailable + `) AS unavailable, NOT (` + belowCapacity + `) AS at_capacity
+		FROM users u
+		LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+		LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+		LEFT JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		WHERE u.id = $1
+		GROUP BY u.id, u.max_open_reviews, ts.max_open_reviews
+	`
+	err := ur.db.QueryRow(ctx, query, userID).Scan(&availability.Unavailable, &availability.AtCapacity)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New("пользователь не найден")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось проверить доступность пользователя %v: %v", userID, err)
+	}
+	return &availability, nil
+}
+
+// GetCodeOwnerCandidates ищет активных пользователей по username или id, либо по участию в команде,
+// порядок такой же, как у GetReviewCandidates
+func (ur *userRepo) GetCodeOwnerCandidates(ctx context.Context, handles, teamNames, excludeIDs []string) ([]models.ReviewCandidate, error) {
+	var candidates []models.ReviewCandidate
+
+	if handles == nil {
+		handles = []string{}
+	}
+	if teamNames == nil {
+		teamNames = []string{}
+	}
+	if excludeIDs == nil {
+		excludeIDs = []string{}
+	}
+
+	query := `
+		SELECT u.id, u.username, COALESCE(u.team_name, ''), COUNT(p.id) AS open_reviews
+		FROM users u
+		LEFT JOIN team_settings ts ON ts.team_name = u.team_name
+		LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id
+		LEFT JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN'
+		WHERE (u.username = ANY($1) OR u.id = ANY($1) OR ` + memberOfTeams + `)
+			AND u.is_active AND NOT (u.id = ANY($3))
+			AND ` + notUnavailable + `
+		GROUP BY u.id, u.username, u.team_name, u.max_open_reviews, ts.max_open_reviews
+		HAVING ` + belowCapacity + `
+		ORDER BY open_reviews, random()
+	`
+	rows, err := ur.db.Query(ctx, query, handles, teamNames, excludeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении владельцев кода: %v", err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var candidate models.ReviewCandidate
+		err := rows.Scan(&candidate.UserID, &candidate.Username, &candidate.TeamName, &candidate.OpenReviews)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при скане строки: %v", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка сканирования строк: %v", err)
+	}
+
+	return candidates, nil
+}
+
+func (ur *userRepo) SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error) {
+	var user models.User
+
+	query := `
+		UPDATE users
+		SET skills = $1
+		WHERE id = $2
+		RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+	`
+	row := ur.db.QueryRow(ctx, query, models.NormalizeTags(skills), userID)
+	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New("пользователь не найден")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не получилось изменить навыки пользователя: %v", err)
+	}
+
+	return &user, nil
+}
+
+func (ur *userRepo) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews int) (*models.User, error) {
+	var user models.User
+
+	query := `
+		UPDATE users
+		SET max_open_reviews = $1
+		WHERE id = $2
+		RETURNING id, username, COALESCE(team_name, ''), is_active, skills, max_open_reviews
+	`
+	row := ur.db.QueryRow(ctx, query, maxOpenReviews, userID)
+	err := row.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills, &user.MaxOpenReviews)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New("пользователь не найден")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не получилось изменить лимит ревью пользователя: %v", err)
+	}
+
+	return &user, nil
+}
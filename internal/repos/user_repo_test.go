@@ -71,16 +71,18 @@ func TestUserRepo_GetUser(t *testing.T) {
 
 	t.Run("успешное получение пользователя", func(t *testing.T) {
 		expectedUser := &models.User{
-			ID:       userID,
-			Username: "testtt",
-			TeamName: "cool_team",
-			IsActive: true,
+			ID:             userID,
+			Username:       "testtt",
+			TeamName:       "cool_team",
+			IsActive:       true,
+			Skills:         []string{"go"},
+			MaxOpenReviews: 5,
 		}
 
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews FROM users WHERE id = \$1`).
 			WithArgs(userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active"}).
-				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.TeamName, expectedUser.IsActive))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.TeamName, expectedUser.IsActive, expectedUser.Skills, expectedUser.MaxOpenReviews))
 
 		user, err := repo.GetUser(ctx, userID)
 
@@ -90,7 +92,7 @@ func TestUserRepo_GetUser(t *testing.T) {
 	})
 
 	t.Run("пользователь не найден", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews FROM users WHERE id = \$1`).
 			WithArgs(userID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -103,7 +105,7 @@ func TestUserRepo_GetUser(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews FROM users WHERE id = \$1`).
 			WithArgs(userID).
 			WillReturnError(errors.New("ошибка базы данных"))
 
@@ -130,11 +132,12 @@ func TestUserRepo_UpsertUser(t *testing.T) {
 		Username: "cool_username",
 		TeamName: "cool_team",
 		IsActive: true,
+		Skills:   []string{" Go", "postgres", "go"},
 	}
 
 	t.Run("успешное создание/обновление пользователя", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO users \(id, username, team_name, is_active\) VALUES \(\$1, \$2, \$3, \$4\) ON CONFLICT \(id\) DO UPDATE SET username = EXCLUDED\.username, team_name = EXCLUDED\.team_name, is_active = EXCLUDED\.is_active`).
-			WithArgs(user.ID, user.Username, user.TeamName, user.IsActive).
+		mock.ExpectExec(`INSERT INTO users \(id, username, team_name, is_active, skills\) VALUES \(\$1, \$2, NULLIF\(\$3, ''\), \$4, \$5\) ON CONFLICT \(id\) DO UPDATE SET username = EXCLUDED\.username, team_name = EXCLUDED\.team_name, is_active = EXCLUDED\.is_active, skills = EXCLUDED\.skills`).
+			WithArgs(user.ID, user.Username, user.TeamName, user.IsActive, []string{"go", "postgres"}).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err := repo.UpsertUser(ctx, user)
@@ -144,8 +147,8 @@ func TestUserRepo_UpsertUser(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO users \(id, username, team_name, is_active\) VALUES \(\$1, \$2, \$3, \$4\) ON CONFLICT \(id\) DO UPDATE SET username = EXCLUDED\.username, team_name = EXCLUDED\.team_name, is_active = EXCLUDED\.is_active`).
-			WithArgs(user.ID, user.Username, user.TeamName, user.IsActive).
+		mock.ExpectExec(`INSERT INTO users \(id, username, team_name, is_active, skills\) VALUES \(\$1, \$2, NULLIF\(\$3, ''\), \$4, \$5\) ON CONFLICT \(id\) DO UPDATE SET username = EXCLUDED\.username, team_name = EXCLUDED\.team_name, is_active = EXCLUDED\.is_active, skills = EXCLUDED\.skills`).
+			WithArgs(user.ID, user.Username, user.TeamName, user.IsActive, []string{"go", "postgres"}).
 			WillReturnError(errors.New("ошибка базы данных"))
 
 		err := repo.UpsertUser(ctx, user)
@@ -174,12 +177,13 @@ func TestUserRepo_SetUserActive(t *testing.T) {
 			Username: "good_username",
 			TeamName: "good_teamname",
 			IsActive: isActive,
+			Skills:   []string{},
 		}
 
-		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, team_name, is_active`).
+		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`).
 			WithArgs(isActive, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active"}).
-				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.TeamName, expectedUser.IsActive))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.TeamName, expectedUser.IsActive, expectedUser.Skills, expectedUser.MaxOpenReviews))
 
 		user, err := repo.SetUserActive(ctx, userID, isActive)
 
@@ -189,7 +193,7 @@ func TestUserRepo_SetUserActive(t *testing.T) {
 	})
 
 	t.Run("пользователь не найден", func(t *testing.T) {
-		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, team_name, is_active`).
+		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`).
 			WithArgs(isActive, userID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -202,7 +206,7 @@ func TestUserRepo_SetUserActive(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, team_name, is_active`).
+		mock.ExpectQuery(`UPDATE users SET is_active = \$1 WHERE id = \$2 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`).
 			WithArgs(isActive, userID).
 			WillReturnError(errors.New("ошибка базы данных"))
 
@@ -215,6 +219,583 @@ func TestUserRepo_SetUserActive(t *testing.T) {
 	})
 }
 
+func TestUserRepo_DeactivateUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	userID := "userid1"
+	moves := []models.Reassignment{
+		{PRID: "pr-0001", OldReviewerID: userID, NewReviewerID: "userid2", Reason: models.ReasonDeactivated},
+	}
+	deactivateQuery := `UPDATE users SET is_active = false WHERE id = \$1 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`
+	moveQuery := `UPDATE pr_reviewers r SET reviewer_id = m.new_id, assigned_via = m.source, assigned_at = now\(\) FROM unnest\(\$1::text\[\], \$2::text\[\], \$3::text\[\], \$4::text\[\]\) AS m\(pr_id, old_id, new_id, source\)`
+
+	t.Run("деактивация с переназначением ревью", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deactivateQuery).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(userID, "alice", "backend", false, []string{}, 0))
+		mock.ExpectExec(moveQuery).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments \(pr_id, old_reviewer_id, new_reviewer_id, reason\) SELECT \* FROM unnest`).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"DEACTIVATED"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		user, err := repo.DeactivateUser(ctx, userID, moves, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.User{ID: userID, Username: "alice", TeamName: "backend", IsActive: false, Skills: []string{}}, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("позиции ротации сохраняются в той же транзакции", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deactivateQuery).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(userID, "alice", "backend", false, []string{}, 0))
+		mock.ExpectExec(moveQuery).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments`).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"DEACTIVATED"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`INSERT INTO team_rotation \(team_name, last_reviewer_id\) SELECT \* FROM unnest\(\$1::text\[\], \$2::text\[\]\) ON CONFLICT \(team_name\) DO UPDATE SET last_reviewer_id = EXCLUDED.last_reviewer_id`).
+			WithArgs([]string{"backend", "platform"}, []string{"userid2", "userid5"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		user, err := repo.DeactivateUser(ctx, userID, moves, map[string]string{"platform": "userid5", "backend": "userid2"})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пользователь не найден", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deactivateQuery).
+			WithArgs(userID).
+			WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		user, err := repo.DeactivateUser(ctx, userID, moves, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, "пользователь не найден", err.Error())
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ревьюер уже снят - откат транзакции", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deactivateQuery).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(userID, "alice", "backend", false, []string{}, 0))
+		mock.ExpectExec(moveQuery).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		user, err := repo.DeactivateUser(ctx, userID, moves, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "назначения ревьюеров изменились")
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_DeactivateUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	userIDs := []string{"userid1", "userid2"}
+
+	t.Run("деактивация без ревью для переназначения", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE users u SET is_active = false WHERE EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\) AND u.id = ANY\(\$2\) RETURNING u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active, u.skills, u.max_open_reviews`).
+			WithArgs("backend", userIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid1", "alice", "backend", false, []string{}, 0).
+				AddRow("userid2", "bob", "backend", false, []string{}, 0))
+		mock.ExpectCommit()
+
+		users, err := repo.DeactivateUsers(ctx, "backend", userIDs, nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE users u SET is_active = false`).
+			WithArgs("backend", userIDs).
+			WillReturnError(errors.New("ошибка базы данных"))
+		mock.ExpectRollback()
+
+		users, err := repo.DeactivateUsers(ctx, "backend", userIDs, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не получилось деактивировать пользователей")
+		assert.Nil(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_GetUsersByIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+
+	t.Run("возвращаются только найденные пользователи", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews FROM users WHERE id = ANY\(\$1\)`).
+			WithArgs([]string{"userid1", "userid9"}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid1", "alice", "backend", true, []string{"go"}, 3))
+
+		users, err := repo.GetUsersByIDs(ctx, []string{"userid1", "userid9"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{{ID: "userid1", Username: "alice", TeamName: "backend", IsActive: true, Skills: []string{"go"}, MaxOpenReviews: 3}}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_ListSCIMUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	columns := []string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews", "teams"}
+
+	t.Run("пользователи со всеми командами, без команды - с пустой", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users u WHERE TRUE$`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(5))
+		mock.ExpectQuery(`SELECT u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active, u.skills, u.max_open_reviews, ARRAY\(SELECT tm.team_name FROM team_members tm WHERE tm.user_id = u.id ORDER BY tm.team_name\) AS teams FROM users u WHERE TRUE ORDER BY u.id OFFSET \$1 LIMIT \$2`).
+			WithArgs(2, 2).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("userid1", "alice", "backend", true, []string{"go"}, 0, []string{"backend", "platform"}).
+				AddRow("userid2", "bob", "", false, []string{}, 0, []string{}))
+
+		users, total, err := repo.ListSCIMUsers(ctx, models.SCIMQuery{Offset: 2, Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.Equal(t, []models.UserWithTeams{
+			{User: models.User{ID: "userid1", Username: "alice", TeamName: "backend", IsActive: true, Skills: []string{"go"}}, Teams: []string{"backend", "platform"}},
+			{User: models.User{ID: "userid2", Username: "bob", TeamName: "", IsActive: false, Skills: []string{}}, Teams: []string{}},
+		}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("фильтр по команде, активности и неизвестному атрибуту", func(t *testing.T) {
+		query := models.SCIMQuery{
+			Conditions: []models.SCIMCondition{
+				{Attr: "groups.value", Op: "ne", Value: "backend"},
+				{Attr: "active", Op: "eq", Value: "true"},
+				{Attr: "emails", Op: "pr"},
+			},
+			Limit: 10,
+		}
+		where := `WHERE TRUE AND NOT \(EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND lower\(tm.team_name\) = lower\(\$1\)\)\) AND lower\(u.is_active::text\) = lower\(\$2\) AND FALSE`
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users u `+where).
+			WithArgs("backend", "true").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(where+` ORDER BY u.id OFFSET \$3 LIMIT \$4`).
+			WithArgs("backend", "true", 0, 10).
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		users, total, err := repo.ListSCIMUsers(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users u`).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT u.id, u.username`).
+			WithArgs(0, 10).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		users, total, err := repo.ListSCIMUsers(ctx, models.SCIMQuery{Limit: 10})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось получить пользователей")
+		assert.Nil(t, users)
+		assert.Zero(t, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_ListUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active, u.skills, u.max_open_reviews FROM users u WHERE \(\$1::text = '' OR EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\)\)`
+
+	t.Run("фильтры и курсор передаются параметрами", func(t *testing.T) {
+		active := true
+		filter := models.UserListFilter{TeamName: "backend", IsActive: &active, UsernamePrefix: "al_", Limit: 2, AfterID: "userid1"}
+
+		mock.ExpectQuery(query).
+			WithArgs("backend", &active, `al\_`, "userid1", 2).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid2", "al_ice", "backend", true, []string{}, 0))
+
+		users, err := repo.ListUsers(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{{ID: "userid2", Username: "al_ice", TeamName: "backend", IsActive: true, Skills: []string{}}}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("", pgxmock.AnyArg(), "", "", 50).
+			WillReturnError(errors.New("db error"))
+
+		users, err := repo.ListUsers(ctx, models.UserListFilter{Limit: 50})
+
+		assert.Error(t, err)
+		assert.Nil(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_DeleteUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	userID := "userid1"
+	moves := []models.Reassignment{
+		{PRID: "pr-0001", OldReviewerID: userID, NewReviewerID: "userid2", Reason: models.ReasonDeleted},
+	}
+	deleteQuery := `DELETE FROM users WHERE id = \$1 RETURNING id, username, COALESCE\(team_name, ''\), is_active, skills, max_open_reviews`
+
+	t.Run("ревью переназначаются до удаления", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE pr_reviewers r SET reviewer_id = m.new_id`).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments \(pr_id, old_reviewer_id, new_reviewer_id, reason\) SELECT \* FROM unnest`).
+			WithArgs([]string{"pr-0001"}, []string{userID}, []string{"userid2"}, []string{"DELETED"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow(userID, "alice", "backend", true, []string{}, 0))
+		mock.ExpectCommit()
+
+		user, err := repo.DeleteUser(ctx, userID, moves, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.User{ID: userID, Username: "alice", TeamName: "backend", IsActive: true, Skills: []string{}}, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пользователь не найден", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(userID).
+			WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		user, err := repo.DeleteUser(ctx, userID, nil, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, "пользователь не найден", err.Error())
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_RemoveTeamMembers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	userIDs := []string{"userid1"}
+	secondaryQuery := `DELETE FROM team_members tm USING users u WHERE u.id = tm.user_id AND tm.team_name = \$1 AND tm.user_id = ANY\(\$2\)`
+
+	t.Run("участник остается без команды", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE users SET is_active = false, team_name = NULL WHERE team_name = \$1 AND id = ANY\(\$2\)`).
+			WithArgs("backend", userIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid1", "alice", "", false, []string{}, 0))
+		mock.ExpectExec(`DELETE FROM team_members WHERE user_id = ANY\(\$1\)`).
+			WithArgs(userIDs).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectQuery(secondaryQuery).
+			WithArgs("backend", userIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}))
+		mock.ExpectCommit()
+
+		users, err := repo.RemoveTeamMembers(ctx, "backend", userIDs, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{{ID: "userid1", Username: "alice", Skills: []string{}}}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("дополнительный участник остается в основной команде", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE users SET is_active = false, team_name = NULL`).
+			WithArgs("backend", userIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}))
+		mock.ExpectExec(`DELETE FROM team_members WHERE user_id = ANY\(\$1\)`).
+			WithArgs([]string{}).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectQuery(secondaryQuery).
+			WithArgs("backend", userIDs).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid1", "alice", "frontend", true, []string{}, 0))
+		mock.ExpectCommit()
+
+		users, err := repo.RemoveTeamMembers(ctx, "backend", userIDs, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.User{{ID: "userid1", Username: "alice", TeamName: "frontend", IsActive: true, Skills: []string{}}}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_AddTeamMembership(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+
+	t.Run("команда добавляется как дополнительная", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO team_members \(team_name, user_id\) VALUES \(\$1, \$2\) ON CONFLICT DO NOTHING`).
+			WithArgs("backend", "userid1").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err := repo.AddTeamMembership(ctx, "backend", "userid1")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_GetUserTeams(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT team_name FROM team_members WHERE user_id = \$1 ORDER BY team_name`
+
+	t.Run("основная и дополнительные команды", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1").
+			WillReturnRows(pgxmock.NewRows([]string{"team_name"}).
+				AddRow("backend").
+				AddRow("platform"))
+
+		teams, err := repo.GetUserTeams(ctx, "userid1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"backend", "platform"}, teams)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1").
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		teams, err := repo.GetUserTeams(ctx, "userid1")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось получить команды пользователя")
+		assert.Nil(t, teams)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_FilterTeamMembers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT user_id FROM team_members WHERE team_name = \$1 AND user_id = ANY\(\$2\) ORDER BY user_id`
+
+	t.Run("участники основной и дополнительной команды", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("backend", []string{"userid1", "userid2", "userid3"}).
+			WillReturnRows(pgxmock.NewRows([]string{"user_id"}).
+				AddRow("userid1").
+				AddRow("userid3"))
+
+		members, err := repo.FilterTeamMembers(ctx, "backend", []string{"userid1", "userid2", "userid3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"userid1", "userid3"}, members)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("backend", []string{"userid1"}).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		members, err := repo.FilterTeamMembers(ctx, "backend", []string{"userid1"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось получить участников команды")
+		assert.Nil(t, members)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_MoveUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	moves := []models.Reassignment{
+		{PRID: "pr-0001", OldReviewerID: "userid1", NewReviewerID: "userid2", Reason: models.ReasonReorg},
+	}
+	moveUserQuery := `UPDATE users SET team_name = \$1 WHERE id = \$2 AND COALESCE\(team_name, ''\) = \$3`
+
+	t.Run("перевод с передачей ревью прежней команде", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(moveUserQuery).
+			WithArgs("frontend", "userid1", "backend").
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "is_active", "skills", "max_open_reviews"}).
+				AddRow("userid1", "alice", "frontend", true, []string{}, 0))
+		mock.ExpectExec(`UPDATE pr_reviewers r SET reviewer_id = m.new_id`).
+			WithArgs([]string{"pr-0001"}, []string{"userid1"}, []string{"userid2"}, []string{"TEAM"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(`INSERT INTO pr_reassignments`).
+			WithArgs([]string{"pr-0001"}, []string{"userid1"}, []string{"userid2"}, []string{"REORG"}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		user, err := repo.MoveUser(ctx, "userid1", "backend", "frontend", moves)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "frontend", user.TeamName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пользователь уже в другой команде", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(moveUserQuery).
+			WithArgs("frontend", "userid1", "backend").
+			WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		user, err := repo.MoveUser(ctx, "userid1", "backend", "frontend", moves)
+
+		assert.Error(t, err)
+		assert.Equal(t, "пользователь не найден в команде", err.Error())
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_GetTeamWorkload(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT u\.id, u\.username, COALESCE\(u\.team_name, ''\), COUNT\(p\.id\) AS open_reviews, COALESCE\(NULLIF\(u\.max_open_reviews, 0\), ts\.max_open_reviews, 0\) AS max_open_reviews, u\.skills FROM users u`
+
+	t.Run("нагрузка участников команды", func(t *testing.T) {
+		expected := []models.ReviewCandidate{
+			{UserID: "userid3", Username: "carol", TeamName: "backend", OpenReviews: 2, MaxOpenReviews: 5, Skills: []string{"go"}},
+		}
+
+		mock.ExpectQuery(query).
+			WithArgs("backend", []string{"userid1"}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews", "skills"}).
+				AddRow("userid3", "carol", "backend", 2, 5, []string{"go"}))
+
+		candidates, err := repo.GetTeamWorkload(ctx, "backend", []string{"userid1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("backend", []string{}).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		candidates, err := repo.GetTeamWorkload(ctx, "backend", nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении нагрузки команды")
+		assert.Nil(t, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	assert.NoError(t, err)
@@ -238,7 +819,7 @@ func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 				ID:       "userid2",
 				Username: "user2",
 				TeamName: teamName,
-				IsActive: false,
+				IsActive: true,
 			},
 		}
 
@@ -246,7 +827,7 @@ func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 			AddRow(expectedUsers[0].ID, expectedUsers[0].Username, expectedUsers[0].TeamName, expectedUsers[0].IsActive).
 			AddRow(expectedUsers[1].ID, expectedUsers[1].Username, expectedUsers[1].TeamName, expectedUsers[1].IsActive)
 
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active FROM users u WHERE EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\) AND u.is_active`).
 			WithArgs(teamName).
 			WillReturnRows(rows)
 
@@ -258,7 +839,7 @@ func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 	})
 
 	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active FROM users u WHERE EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\) AND u.is_active`).
 			WithArgs(teamName).
 			WillReturnError(errors.New("ошибка базы данных"))
 
@@ -272,9 +853,9 @@ func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 
 	t.Run("ошибка при сканировании строки", func(t *testing.T) {
 		rows := pgxmock.NewRows([]string{"id", "username", "team_name", "is_active"}).
-			AddRow("invalid-uuid", "user1", teamName, true)
+			AddRow("invalid-uuid", "user1", teamName, "not-a-bool")
 
-		mock.ExpectQuery(`SELECT id, username, team_name, is_active FROM users WHERE team_name = \$1`).
+		mock.ExpectQuery(`SELECT u.id, u.username, COALESCE\(u.team_name, ''\), u.is_active FROM users u WHERE EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\) AND u.is_active`).
 			WithArgs(teamName).
 			WillReturnRows(rows)
 
@@ -286,3 +867,204 @@ func TestUserRepo_GetActiveUsersByTeam(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestUserRepo_GetReviewCandidates(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	filter := models.CandidateFilter{
+		TeamName:   "team123",
+		ExcludeIDs: []string{"userid1"},
+		Skills:     []string{"Backend"},
+		Limit:      2,
+	}
+	query := `SELECT u\.id, u\.username, COALESCE\(u\.team_name, ''\), COUNT\(p\.id\) AS open_reviews, COALESCE\(NULLIF\(u\.max_open_reviews, 0\), ts\.max_open_reviews, 0\) AS max_open_reviews FROM users u .* HAVING \$5::boolean OR COUNT\(p\.id\) < .* ORDER BY open_reviews, random\(\) LIMIT NULLIF\(\$3::int, 0\)`
+
+	t.Run("кандидаты упорядочены по нагрузке", func(t *testing.T) {
+		expected := []models.ReviewCandidate{
+			{UserID: "userid2", Username: "user2", TeamName: "team123", OpenReviews: 0},
+			{UserID: "userid3", Username: "user3", TeamName: "team123", OpenReviews: 4, MaxOpenReviews: 5},
+		}
+
+		rows := pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews"}).
+			AddRow(expected[0].UserID, expected[0].Username, expected[0].TeamName, expected[0].OpenReviews, expected[0].MaxOpenReviews).
+			AddRow(expected[1].UserID, expected[1].Username, expected[1].TeamName, expected[1].OpenReviews, expected[1].MaxOpenReviews)
+
+		mock.ExpectQuery(query).
+			WithArgs(filter.TeamName, filter.ExcludeIDs, filter.Limit, []string{"backend"}, false, []string{}).
+			WillReturnRows(rows)
+
+		candidates, err := repo.GetReviewCandidates(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пустой список исключений не передается как NULL", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("team123", []string{}, 0, []string{}, false, []string{}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews"}))
+
+		candidates, err := repo.GetReviewCandidates(ctx, models.CandidateFilter{TeamName: "team123"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("участие в команде проверяется без соединения с team_members", func(t *testing.T) {
+		// соединение размножило бы строки пользователя и его OPEN ревью
+		mock.ExpectQuery(`FROM users u LEFT JOIN team_settings ts ON ts.team_name = u.team_name LEFT JOIN pr_reviewers r ON r.reviewer_id = u.id LEFT JOIN pull_requests p ON p.id = r.pr_id AND p.status = 'OPEN' WHERE \(EXISTS \(SELECT 1 FROM team_members tm WHERE tm.user_id = u.id AND tm.team_name = \$1\)`).
+			WithArgs("team123", []string{}, 0, []string{}, false, []string{}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews"}).
+				AddRow("userid2", "user2", "team456", 1, 0))
+
+		candidates, err := repo.GetReviewCandidates(ctx, models.CandidateFilter{TeamName: "team123"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReviewCandidate{{UserID: "userid2", Username: "user2", TeamName: "team456", OpenReviews: 1}}, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("участники пулов подбираются вместе с участниками команды", func(t *testing.T) {
+		mock.ExpectQuery(`OR EXISTS \(SELECT 1 FROM reviewer_pool_members pm WHERE pm.user_id = u.id AND pm.pool_name = ANY\(\$6\)\)\) AND u.is_active`).
+			WithArgs("team123", []string{}, 0, []string{}, false, []string{"security-champions"}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews"}).
+				AddRow("userid7", "user7", "platform", 0, 0))
+
+		candidates, err := repo.GetReviewCandidates(ctx, models.CandidateFilter{
+			TeamName: "team123",
+			Pools:    []string{"security-champions"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReviewCandidate{{UserID: "userid7", Username: "user7", TeamName: "platform", OpenReviews: 0}}, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("участник пула без команды", func(t *testing.T) {
+		// users.team_name пустой у пользователей, созданных через SCIM или исключенных из команды
+		mock.ExpectQuery(query).
+			WithArgs("team123", []string{}, 0, []string{}, false, []string{"security-champions"}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews", "max_open_reviews"}).
+				AddRow("userid8", "user8", "", 0, 0))
+
+		candidates, err := repo.GetReviewCandidates(ctx, models.CandidateFilter{
+			TeamName: "team123",
+			Pools:    []string{"security-champions"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReviewCandidate{{UserID: "userid8", Username: "user8", TeamName: "", OpenReviews: 0}}, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(filter.TeamName, filter.ExcludeIDs, filter.Limit, []string{"backend"}, false, []string{}).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		candidates, err := repo.GetReviewCandidates(ctx, filter)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении кандидатов в ревьюеры команды")
+		assert.Nil(t, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_GetCodeOwnerCandidates(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT u\.id, u\.username, COALESCE\(u\.team_name, ''\), COUNT\(p\.id\) AS open_reviews FROM users u .* WHERE \(u\.username = ANY\(\$1\) OR u\.id = ANY\(\$1\) OR EXISTS`
+
+	t.Run("владелец по handle без команды", func(t *testing.T) {
+		// пользователь, созданный через SCIM, остается без команды, пока его не добавят в группу
+		mock.ExpectQuery(query).
+			WithArgs([]string{"alice"}, []string{}, []string{"userid5"}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "username", "team_name", "open_reviews"}).
+				AddRow("userid1", "alice", "", 0))
+
+		candidates, err := repo.GetCodeOwnerCandidates(ctx, []string{"alice"}, nil, []string{"userid5"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReviewCandidate{{UserID: "userid1", Username: "alice", TeamName: "", OpenReviews: 0}}, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]string{}, []string{"backend"}, []string{}).
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		candidates, err := repo.GetCodeOwnerCandidates(ctx, nil, []string{"backend"}, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ошибка при получении владельцев кода")
+		assert.Nil(t, candidates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepo_GetReviewerAvailability(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &MockDB{mock: mock}
+	repo := repos.NewUserRepo(db)
+
+	ctx := context.Background()
+	query := `SELECT NOT \(NOT EXISTS \( SELECT 1 FROM user_unavailability ua .*\) AS unavailable, NOT \(COUNT\(p.id\) < .*\) AS at_capacity FROM users u`
+
+	t.Run("пользователь в отпуске и на пределе нагрузки", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1").
+			WillReturnRows(pgxmock.NewRows([]string{"unavailable", "at_capacity"}).
+				AddRow(true, true))
+
+		availability, err := repo.GetReviewerAvailability(ctx, "userid1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ReviewerAvailability{Unavailable: true, AtCapacity: true}, availability)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("пользователь не найден", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1").
+			WillReturnError(pgx.ErrNoRows)
+
+		availability, err := repo.GetReviewerAvailability(ctx, "userid1")
+
+		assert.Error(t, err)
+		assert.Equal(t, "пользователь не найден", err.Error())
+		assert.Nil(t, availability)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ошибка при выполнении запроса", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("userid1").
+			WillReturnError(errors.New("ошибка базы данных"))
+
+		availability, err := repo.GetReviewerAvailability(ctx, "userid1")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось проверить доступность пользователя")
+		assert.Nil(t, availability)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -8,34 +8,98 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func InitRoutes(e *echo.Echo, db *database.DB) {
+func InitRoutes(e *echo.Echo, db *database.DB, prService services.PRService) {
 	userRepo := repos.NewUserRepo(db)
 	prRepo := repos.NewPRRepo(db)
 	teamRepo := repos.NewTeamRepo(db)
+	codeOwnersRepo := repos.NewCodeOwnersRepo(db)
+	unavailabilityRepo := repos.NewUnavailabilityRepo(db)
+	poolRepo := repos.NewReviewerPoolRepo(db)
 
-	userService := services.NewUserService(userRepo, prRepo)
-	prService := services.NewPRService(prRepo, userRepo)
-	teamService := services.NewTeamService(teamRepo, userRepo)
+	userService := services.NewUserService(userRepo, prRepo, unavailabilityRepo, teamRepo, prService)
+	teamService := services.NewTeamService(teamRepo, userRepo, prRepo, prService)
 	statsService := services.NewStatsService(prRepo, userRepo)
+	codeOwnersService := services.NewCodeOwnersService(codeOwnersRepo, teamRepo)
+	scimService := services.NewSCIMService(userRepo, teamRepo, userService, teamService)
+	poolService := services.NewReviewerPoolService(poolRepo, userRepo, teamRepo)
 
 	userHandler := handlers.NewUserHandler(userService)
 	prHandler := handlers.NewPRHandler(prService)
 	teamHandler := handlers.NewTeamHandler(teamService)
 	statsHandler := handlers.NewStatsHandler(statsService)
+	codeOwnersHandler := handlers.NewCodeOwnersHandler(codeOwnersService)
+	scimHandler := handlers.NewSCIMHandler(scimService)
+	poolHandler := handlers.NewReviewerPoolHandler(poolService)
 
 	// users
 	e.POST("/users/setIsActive", userHandler.SetUserActive)
 	e.GET("/users/getReview", userHandler.GetPRsByReviewer)
+	e.POST("/users/setSkills", userHandler.SetUserSkills)
+	e.POST("/users/setMaxOpenReviews", userHandler.SetUserMaxOpenReviews)
+	e.POST("/users/addUnavailability", userHandler.AddUnavailability)
+	e.GET("/users/getUnavailability", userHandler.GetUnavailability)
+	e.POST("/users/updateUnavailability", userHandler.UpdateUnavailability)
+	e.POST("/users/deleteUnavailability", userHandler.DeleteUnavailability)
+	e.GET("/users/get", userHandler.GetUser)
+	e.GET("/users/list", userHandler.ListUsers)
+	e.POST("/users/delete", userHandler.DeleteUser)
 
 	// pull requests
 	e.POST("/pullRequest/create", prHandler.CreatePR)
 	e.POST("/pullRequest/merge", prHandler.MergePR)
 	e.POST("/pullRequest/reassign", prHandler.ReassignReviewer)
+	e.POST("/pullRequest/review", prHandler.SubmitReview)
+	e.POST("/pullRequest/addReviewer", prHandler.AddReviewer)
+	e.POST("/pullRequest/removeReviewer", prHandler.RemoveReviewer)
+	e.POST("/pullRequest/close", prHandler.ClosePR)
+	e.POST("/pullRequest/reopen", prHandler.ReopenPR)
+	e.POST("/pullRequest/markReady", prHandler.MarkReady)
+	e.GET("/pullRequest/get", prHandler.GetPR)
+	e.GET("/pullRequest/list", prHandler.ListPRs)
+	e.GET("/pullRequest/understaffed", prHandler.ListUnderstaffed)
 
 	// teams
 	e.POST("/team/add", teamHandler.CreateTeam)
 	e.GET("/team/get", teamHandler.GetTeam)
+	e.GET("/team/settings", teamHandler.GetTeamSettings)
+	e.POST("/team/settings", teamHandler.UpdateTeamSettings)
+	e.POST("/team/deactivateUsers", teamHandler.DeactivateUsers)
+	e.POST("/team/addMembers", teamHandler.AddMembers)
+	e.POST("/team/removeMembers", teamHandler.RemoveMembers)
+	e.POST("/team/moveUser", teamHandler.MoveUser)
+	e.POST("/team/rename", teamHandler.RenameTeam)
+	e.POST("/team/setParent", teamHandler.SetParentTeam)
+	e.POST("/team/delete", teamHandler.DeleteTeam)
+	e.PUT("/team/sync", teamHandler.SyncTeam)
+
+	// codeowners
+	e.POST("/codeowners/upload", codeOwnersHandler.UploadCodeOwners)
+	e.GET("/codeowners/get", codeOwnersHandler.GetCodeOwners)
+
+	// reviewer pools
+	e.POST("/reviewerPool/save", poolHandler.SavePool)
+	e.GET("/reviewerPool/get", poolHandler.GetPool)
+	e.GET("/reviewerPool/list", poolHandler.ListPools)
+	e.POST("/reviewerPool/delete", poolHandler.DeletePool)
+	e.POST("/reviewerPool/attach", poolHandler.AttachPool)
+	e.POST("/reviewerPool/detach", poolHandler.DetachPool)
+	e.GET("/reviewerPool/team", poolHandler.GetTeamPools)
 
 	// stats
 	e.GET("/stats", statsHandler.GetStats)
+
+	// scim
+	scim := e.Group("/scim/v2")
+	scim.GET("/Users", scimHandler.ListUsers)
+	scim.POST("/Users", scimHandler.CreateUser)
+	scim.GET("/Users/:id", scimHandler.GetUser)
+	scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+	scim.PATCH("/Users/:id", scimHandler.PatchUser)
+	scim.DELETE("/Users/:id", scimHandler.DeleteUser)
+	scim.GET("/Groups", scimHandler.ListGroups)
+	scim.POST("/Groups", scimHandler.CreateGroup)
+	scim.GET("/Groups/:id", scimHandler.GetGroup)
+	scim.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+	scim.PATCH("/Groups/:id", scimHandler.PatchGroup)
+	scim.DELETE("/Groups/:id", scimHandler.DeleteGroup)
 }
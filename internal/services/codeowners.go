@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type codeOwnersRule struct {
+	pattern string
+	re      *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners - разобранный CODEOWNERS в синтаксисе GitHub.
+// Правила проверяются снизу вверх, побеждает последнее совпавшее
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+func ParseCodeOwners(content string) (*CodeOwners, error) {
+	co := &CodeOwners{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		fields := strings.Fields(line)
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+			return nil, fmt.Errorf("строка %d: шаблон %q не поддерживается в CODEOWNERS", i+1, pattern)
+		}
+
+		re, err := compileCodeOwnersPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: некорректный шаблон %q: %v", i+1, pattern, err)
+		}
+
+		owners := fields[1:]
+		for _, owner := range owners {
+			if !strings.Contains(owner, "@") {
+				return nil, fmt.Errorf("строка %d: некорректный владелец %q", i+1, owner)
+			}
+		}
+
+		co.rules = append(co.rules, codeOwnersRule{
+			pattern: pattern,
+			re:      re,
+			owners:  owners,
+		})
+	}
+	return co, nil
+}
+
+// Owners возвращает владельцев файла. Правило без владельцев тоже побеждает
+// и снимает владельцев, заданных выше
+func (co *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].re.MatchString(path) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("пустой шаблон")
+	}
+	// как в gitignore: слеш в начале или середине привязывает шаблон к корню
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		ch := trimmed[i]
+		switch {
+		case ch == '*' && i+1 < len(trimmed) && trimmed[i+1] == '*':
+			i++
+			if i+1 < len(trimmed) && trimmed[i+1] == '/' {
+				// "**/" - любое число каталогов, включая ноль
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+
+	switch {
+	case dirOnly:
+		sb.WriteString("/.*$")
+	case strings.HasSuffix(trimmed, "/*"):
+		// docs/* - только файлы непосредственно в каталоге, без вложенных
+		sb.WriteString("$")
+	default:
+		// шаблон, совпавший с каталогом, распространяется на все его содержимое
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
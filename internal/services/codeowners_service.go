@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+)
+
+type CodeOwnersService interface {
+	UploadCodeOwners(ctx context.Context, file *models.CodeOwnersFile) (*models.CodeOwnersFile, error)
+	GetCodeOwners(ctx context.Context, scope models.CodeOwnersScope, name string) (*models.CodeOwnersFile, error)
+}
+
+type codeOwnersService struct {
+	codeOwnersRepo repos.CodeOwnersRepo
+	teamRepo       repos.TeamRepo
+}
+
+func NewCodeOwnersService(codeOwnersRepo repos.CodeOwnersRepo, teamRepo repos.TeamRepo) CodeOwnersService {
+	return &codeOwnersService{
+		codeOwnersRepo: codeOwnersRepo,
+		teamRepo:       teamRepo,
+	}
+}
+
+func (cos *codeOwnersService) UploadCodeOwners(ctx context.Context, file *models.CodeOwnersFile) (*models.CodeOwnersFile, error) {
+	if file.Name == "" || (file.Scope != models.CodeOwnersScopeTeam && file.Scope != models.CodeOwnersScopeRepository) {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	if _, err := ParseCodeOwners(file.Content); err != nil {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	if file.Scope == models.CodeOwnersScopeTeam {
+		exists, err := cos.teamRepo.IsTeamExists(ctx, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !*exists {
+			return nil, errors.New(NOT_FOUND)
+		}
+	}
+
+	err := cos.codeOwnersRepo.UpsertCodeOwners(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	return cos.codeOwnersRepo.GetCodeOwners(ctx, file.Scope, file.Name)
+}
+
+func (cos *codeOwnersService) GetCodeOwners(ctx context.Context, scope models.CodeOwnersScope, name string) (*models.CodeOwnersFile, error) {
+	if name == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	file, err := cos.codeOwnersRepo.GetCodeOwners(ctx, scope, name)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return file, nil
+}
@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `
+# владельцы по умолчанию
+*                   @global-owner
+*.js                @js-owner
+**/logs             @logs-owner
+/build/logs/        @doctocat
+docs/*              docs@example.com
+apps/               @octocat
+/scripts/           @scripts-owner @org/infra
+/apps/github
+`
+	co, err := services.ParseCodeOwners(content)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		path   string
+		owners []string
+	}{
+		{"правило по умолчанию", "README.md", []string{"@global-owner"}},
+		{"расширение на любой глубине", "web/src/app.js", []string{"@js-owner"}},
+		{"побеждает последнее совпавшее правило", "build/logs/2024/app.log", []string{"@doctocat"}},
+		{"каталог от корня не совпадает глубже", "src/build/logs/app.log", []string{"@logs-owner"}},
+		{"файлы непосредственно в каталоге", "docs/getting-started.md", []string{"docs@example.com"}},
+		{"вложенные файлы не совпадают с docs/*", "docs/build-app/troubleshooting.md", []string{"@global-owner"}},
+		{"каталог на любой глубине", "src/apps/main.go", []string{"@octocat"}},
+		{"двойная звездочка", "deep/nested/logs/out.txt", []string{"@logs-owner"}},
+		{"несколько владельцев", "scripts/deploy.sh", []string{"@scripts-owner", "@org/infra"}},
+		{"последнее правило без владельцев снимает владельцев", "apps/github/main.go", nil},
+		{"ведущий слеш в пути", "/scripts/deploy.sh", []string{"@scripts-owner", "@org/infra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owners := co.Owners(tt.path)
+			if tt.owners == nil {
+				assert.Empty(t, owners)
+				return
+			}
+			assert.Equal(t, tt.owners, owners)
+		})
+	}
+}
+
+func TestParseCodeOwners_Invalid(t *testing.T) {
+	t.Run("отрицание не поддерживается", func(t *testing.T) {
+		_, err := services.ParseCodeOwners("!*.go @owner")
+		assert.Error(t, err)
+	})
+
+	t.Run("диапазоны символов не поддерживаются", func(t *testing.T) {
+		_, err := services.ParseCodeOwners("*.[ch] @owner")
+		assert.Error(t, err)
+	})
+
+	t.Run("некорректный владелец", func(t *testing.T) {
+		_, err := services.ParseCodeOwners("*.go owner")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,23 @@
+package services
+
+// EscalationOrder возвращает команды, в которых ищутся ревьюеры, если в teamName их не хватило:
+// для каждого предка, начиная с ближайшего, сначала сам предок, затем остальные его дочерние команды.
+// Каждая команда встречается один раз, сама teamName в порядок не входит
+func EscalationOrder(teamName string, ancestors []string, children map[string][]string) []string {
+	seen := map[string]bool{teamName: true}
+	var order []string
+	visit := func(team string) {
+		if !seen[team] {
+			seen[team] = true
+			order = append(order, team)
+		}
+	}
+
+	for _, ancestor := range ancestors {
+		visit(ancestor)
+		for _, child := range children[ancestor] {
+			visit(child)
+		}
+	}
+	return order
+}
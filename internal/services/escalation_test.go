@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscalationOrder(t *testing.T) {
+	t.Run("корневая команда не эскалируется", func(t *testing.T) {
+		assert.Empty(t, services.EscalationOrder("platform", nil, nil))
+	})
+
+	t.Run("родитель раньше соседей, ближайший предок раньше дальнего", func(t *testing.T) {
+		ancestors := []string{"backend", "engineering"}
+		children := map[string][]string{
+			"backend":     {"billing", "payments", "search"},
+			"engineering": {"backend", "frontend", "mobile"},
+		}
+
+		order := services.EscalationOrder("payments", ancestors, children)
+
+		assert.Equal(t, []string{"backend", "billing", "search", "engineering", "frontend", "mobile"}, order)
+	})
+
+	t.Run("цикл в иерархии не дает повторов", func(t *testing.T) {
+		ancestors := []string{"a", "b"}
+		children := map[string][]string{
+			"a": {"b", "team"},
+			"b": {"a"},
+		}
+
+		order := services.EscalationOrder("team", ancestors, children)
+
+		assert.Equal(t, []string{"a", "b"}, order)
+	})
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+// EncodePRCursor возвращает непрозрачный курсор, указывающий на позицию после pr
+func EncodePRCursor(pr *models.PullRequest, sortBy models.PRSortField, desc bool) string {
+	cursor := models.PRCursor{
+		SortBy: sortBy,
+		Desc:   desc,
+		ID:     pr.ID,
+	}
+	switch sortBy {
+	case models.SortByCreatedAt:
+		cursor.Time = pr.CreatedAt
+	case models.SortByMergedAt:
+		cursor.Time = pr.MergedAt
+	case models.SortByName:
+		cursor.Name = pr.Name
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePRCursor разбирает курсор и проверяет, что он выдан для той же сортировки
+func DecodePRCursor(value string, sortBy models.PRSortField, desc bool) (*models.PRCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New(INVALID_CURSOR)
+	}
+
+	var cursor models.PRCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.New(INVALID_CURSOR)
+	}
+	if cursor.ID == "" || cursor.SortBy != sortBy || cursor.Desc != desc {
+		return nil, errors.New(INVALID_CURSOR)
+	}
+	if sortBy != models.SortByName && cursor.Time == nil {
+		return nil, errors.New(INVALID_CURSOR)
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRCursor(t *testing.T) {
+	createdAt := time.Date(2025, 7, 4, 10, 30, 0, 123456000, time.UTC)
+	pr := &models.PullRequest{
+		ID:        "pr-0001",
+		Name:      "fix build",
+		CreatedAt: &createdAt,
+	}
+
+	t.Run("курсор по дате создания", func(t *testing.T) {
+		value := services.EncodePRCursor(pr, models.SortByCreatedAt, true)
+
+		cursor, err := services.DecodePRCursor(value, models.SortByCreatedAt, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pr-0001", cursor.ID)
+		assert.True(t, createdAt.Equal(*cursor.Time))
+	})
+
+	t.Run("курсор по имени", func(t *testing.T) {
+		value := services.EncodePRCursor(pr, models.SortByName, false)
+
+		cursor, err := services.DecodePRCursor(value, models.SortByName, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fix build", cursor.Name)
+		assert.Nil(t, cursor.Time)
+	})
+
+	t.Run("курсор другой сортировки", func(t *testing.T) {
+		value := services.EncodePRCursor(pr, models.SortByCreatedAt, false)
+
+		_, err := services.DecodePRCursor(value, models.SortByCreatedAt, true)
+		assert.EqualError(t, err, services.INVALID_CURSOR)
+
+		_, err = services.DecodePRCursor(value, models.SortByName, false)
+		assert.EqualError(t, err, services.INVALID_CURSOR)
+	})
+
+	t.Run("поврежденный курсор", func(t *testing.T) {
+		_, err := services.DecodePRCursor("not a cursor!", models.SortByCreatedAt, false)
+		assert.EqualError(t, err, services.INVALID_CURSOR)
+	})
+}
@@ -3,66 +3,237 @@ package services
 import (
 	"context"
 	"errors"
-	"math/rand"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/repos"
 )
 
 type PRService interface {
-	CreatePR(ctx context.Context, prID string, prName string, authorID string) (*models.PullRequest, error)
-	MergePR(ctx context.Context, prID string) (*models.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (*models.PullRequest, string, error)
+	CreatePR(ctx context.Context, req *models.CreatePRRequest) (*models.PullRequest, error)
+	MergePR(ctx context.Context, prID string, force bool) (*models.PullRequest, error)
+	SubmitReview(ctx context.Context, review *models.Review) (*models.Review, error)
+	ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (*models.PullRequest, string, error)
+	ReassignStaleReviews(ctx context.Context, now time.Time) ([]models.Reassignment, error)
+	PlanReassignments(ctx context.Context, reviewerID string, reason models.ReassignReason) (*models.ReassignmentReport, error)
+	AddReviewer(ctx context.Context, req *models.ReviewerChangeRequest) (*models.PullRequest, error)
+	RemoveReviewer(ctx context.Context, req *models.ReviewerChangeRequest) (*models.PullRequest, error)
+	ClosePR(ctx context.Context, prID string) (*models.PullRequest, error)
+	ReopenPR(ctx context.Context, prID string) (*models.PullRequest, error)
+	MarkReady(ctx context.Context, prID string) (*models.PullRequest, error)
+	GetPR(ctx context.Context, prID string) (*models.PullRequest, error)
+	ListPRs(ctx context.Context, filter models.PRListFilter, cursor string) (*models.PRPage, error)
+	ListUnderstaffed(ctx context.Context, teamName string) ([]models.UnderstaffedPR, error)
+	BackfillReviewers(ctx context.Context, teamNames []string) ([]models.ReviewerBackfill, error)
 }
 
 type prService struct {
-	prRepo   repos.PRRepo
-	userRepo repos.UserRepo
+	prRepo         repos.PRRepo
+	userRepo       repos.UserRepo
+	teamRepo       repos.TeamRepo
+	codeOwnersRepo repos.CodeOwnersRepo
+	reviewRepo     repos.ReviewRepo
+	poolRepo       repos.ReviewerPoolRepo
+	selectors      map[models.ReviewerStrategy]ReviewerSelector
 }
 
-func NewPRService(prRepo repos.PRRepo, userRepo repos.UserRepo) PRService {
+func NewPRService(prRepo repos.PRRepo, userRepo repos.UserRepo, teamRepo repos.TeamRepo, codeOwnersRepo repos.CodeOwnersRepo, reviewRepo repos.ReviewRepo, poolRepo repos.ReviewerPoolRepo) PRService {
 	return &prService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
+		prRepo:         prRepo,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		codeOwnersRepo: codeOwnersRepo,
+		reviewRepo:     reviewRepo,
+		poolRepo:       poolRepo,
+		selectors:      defaultSelectors(userRepo, teamRepo),
 	}
 }
 
-func (prs *prService) CreatePR(ctx context.Context, prID string, prName string, authorID string) (*models.PullRequest, error) {
-	_, err := prs.prRepo.GetPRByID(ctx, prID)
-	if err == nil {
-		return nil, errors.New(PR_EXISTS)
+// selectReviewers выбирает ревьюеров стратегией команды. Если в фильтре заданы навыки,
+// сначала берутся кандидаты с совпадающими навыками, а недостающие добираются из общего пула.
+// Если ревьюеров не хватило, возвращаются выбранные и признак того, что часть кандидатов
+// не взяли только из-за лимита нагрузки
+func (prs *prService) selectReviewers(ctx context.Context, strategy models.ReviewerStrategy, filter models.CandidateFilter, count int) ([]string, bool, error) {
+	if count <= 0 {
+		return nil, false, nil
+	}
+
+	selector, ok := prs.selectors[strategy]
+	if !ok {
+		selector = prs.selectors[models.StrategyRandom]
 	}
 
-	author, err := prs.userRepo.GetUser(ctx, authorID)
+	selected, err := selector.Select(ctx, filter, count)
 	if err != nil {
-		return nil, errors.New(NOT_FOUND)
+		return nil, false, err
+	}
+	if len(selected) >= count {
+		return selected, false, nil
+	}
+
+	rest := models.CandidateFilter{
+		TeamName:   filter.TeamName,
+		Pools:      filter.Pools,
+		ExcludeIDs: append(append([]string{}, filter.ExcludeIDs...), selected...),
+	}
+	if len(filter.Skills) > 0 {
+		fallback, err := selector.Select(ctx, rest, count-len(selected))
+		if err != nil {
+			return nil, false, err
+		}
+		selected = append(selected, fallback...)
+		if len(selected) >= count {
+			return selected, false, nil
+		}
+		rest.ExcludeIDs = append(rest.ExcludeIDs, fallback...)
+	}
+
+	// остались ли кандидаты, которых не взяли только из-за лимита
+	rest.IgnoreCapacity = true
+	rest.Limit = 1
+	saturated, err := prs.userRepo.GetReviewCandidates(ctx, rest)
+	if err != nil {
+		return nil, false, err
+	}
+	return selected, len(saturated) > 0, nil
+}
+
+// teamPools делит пулы ревьюеров, подключенные к команде, на дополнительные и резервные
+func (prs *prService) teamPools(ctx context.Context, teamName string) ([]string, []string, error) {
+	teamPools, err := prs.poolRepo.GetTeamPools(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var supplementary, fallback []string
+	for _, teamPool := range teamPools {
+		if teamPool.Mode == models.PoolFallback {
+			fallback = append(fallback, teamPool.PoolName)
+		} else {
+			supplementary = append(supplementary, teamPool.PoolName)
+		}
+	}
+	return supplementary, fallback, nil
+}
+
+// selectFromPools добирает до count ревьюеров из резервных пулов. У пула нет своей стратегии
+// и позиции ротации, поэтому берутся наименее загруженные участники. Пулы, упершиеся
+// в лимит нагрузки, просто не дают кандидатов
+func (prs *prService) selectFromPools(ctx context.Context, pr *models.PullRequest, pools, exclude []string, count int) ([]string, error) {
+	if count <= 0 || len(pools) == 0 {
+		return nil, nil
+	}
+
+	filter := models.CandidateFilter{
+		Pools:      pools,
+		ExcludeIDs: exclude,
+		Skills:     pr.Labels,
+	}
+	selected, _, err := prs.selectReviewers(ctx, models.StrategyLeastLoaded, filter, count)
+	return selected, err
+}
+
+// escalate добирает до count ревьюеров в родительских и соседних командах teamName
+// по EscalationOrder. Каждая команда подбирает кандидатов своей стратегией и отдает столько,
+// сколько позволяет лимит нагрузки ее участников. Вместе с ревьюерами возвращает признак того,
+// что кому-то из кандидатов помешал лимит
+func (prs *prService) escalate(ctx context.Context, pr *models.PullRequest, teamName string, exclude []string, count int) ([]string, bool, error) {
+	if count <= 0 || teamName == "" {
+		return nil, false, nil
 	}
 
-	teamUsers, err := prs.userRepo.GetActiveUsersByTeam(ctx, author.TeamName)
+	ancestors, err := prs.teamRepo.GetTeamAncestors(ctx, teamName)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ancestors) == 0 {
+		return nil, false, nil
+	}
+	children, err := prs.teamRepo.GetChildTeams(ctx, ancestors)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var selected []string
+	anySaturated := false
+	for _, team := range EscalationOrder(teamName, ancestors, children) {
+		settings, err := prs.teamRepo.GetTeamSettings(ctx, team)
+		if err != nil {
+			return nil, false, err
+		}
+
+		filter := models.CandidateFilter{
+			TeamName:   team,
+			ExcludeIDs: append(append([]string{}, exclude...), selected...),
+			Skills:     pr.Labels,
+		}
+		reviewers, saturated, err := prs.selectReviewers(ctx, settings.ReviewerStrategy, filter, count-len(selected))
+		if err != nil {
+			return nil, false, err
+		}
+		anySaturated = anySaturated || saturated
+		selected = append(selected, reviewers...)
+		if len(selected) >= count {
+			return selected, false, nil
+		}
+	}
+	return selected, anySaturated, nil
+}
+
+func (prs *prService) CreatePR(ctx context.Context, req *models.CreatePRRequest) (*models.PullRequest, error) {
+	_, err := prs.prRepo.GetPRByID(ctx, req.ID)
+	if err == nil {
+		return nil, errors.New(PR_EXISTS)
+	}
+
+	author, err := prs.userRepo.GetUser(ctx, req.AuthorID)
 	if err != nil {
 		return nil, errors.New(NOT_FOUND)
 	}
 
-	var reviewers []string
-	for _, user := range teamUsers {
-		if user.ID != authorID && user.IsActive {
-			reviewers = append(reviewers, user.ID)
+	// пулл реквест в свою же команду межкомандным не считается
+	targetTeam := req.TargetTeam
+	if targetTeam == author.TeamName {
+		targetTeam = ""
+	}
+	if targetTeam != "" {
+		exists, err := prs.teamRepo.IsTeamExists(ctx, targetTeam)
+		if err != nil {
+			return nil, err
+		}
+		if !*exists {
+			return nil, errors.New(NOT_FOUND)
 		}
 	}
 
-	if len(reviewers) > 2 {
-		rand.Shuffle(len(reviewers), func(i, j int) {
-			reviewers[i], reviewers[j] = reviewers[j], reviewers[i]
-		})
-		reviewers = reviewers[:2]
+	coAuthors, err := prs.coAuthors(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	newPR := &models.PullRequest{
-		ID:                prID,
-		Name:              prName,
-		AuthorID:          authorID,
-		Status:            models.StatusOpen,
-		AssignedReviewers: reviewers,
+		ID:               req.ID,
+		Name:             req.Name,
+		AuthorID:         req.AuthorID,
+		CoAuthors:        coAuthors,
+		Status:           models.StatusOpen,
+		Labels:           models.NormalizeTags(req.Labels),
+		Repository:       req.Repository,
+		ChangedFiles:     req.ChangedFiles,
+		TargetTeam:       targetTeam,
+		AuthorTeamReview: targetTeam != "" && req.AuthorTeamReview,
+	}
+
+	// ревьюеры черновика назначаются, когда он выходит из DRAFT
+	if req.Draft {
+		newPR.Status = models.StatusDraft
+	} else {
+		err = prs.assignPRReviewers(ctx, newPR, author.TeamName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = prs.prRepo.CreatePR(ctx, newPR)
@@ -73,7 +244,240 @@ func (prs *prService) CreatePR(ctx context.Context, prID string, prName string,
 	return newPR, err
 }
 
-func (prs *prService) MergePR(ctx context.Context, prID string) (*models.PullRequest, error) {
+// coAuthors проверяет соавторов из запроса и возвращает их без повторов и без основного автора
+func (prs *prService) coAuthors(ctx context.Context, req *models.CreatePRRequest) ([]string, error) {
+	var coAuthors []string
+	for _, userID := range req.CoAuthors {
+		if userID == "" {
+			return nil, errors.New(INVALID_INPUT)
+		}
+		if userID != req.AuthorID && !slices.Contains(coAuthors, userID) {
+			coAuthors = append(coAuthors, userID)
+		}
+	}
+	if len(coAuthors) == 0 {
+		return nil, nil
+	}
+
+	users, err := prs.userRepo.GetUsersByIDs(ctx, coAuthors)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != len(coAuthors) {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return coAuthors, nil
+}
+
+// assignPRReviewers назначает ревьюеров из команды, которая ревьюит пулл реквест. Межкомандному
+// пулл реквесту с author_team_review сверх этого назначается один ревьюер из команды автора
+func (prs *prService) assignPRReviewers(ctx context.Context, pr *models.PullRequest, authorTeam string) error {
+	err := prs.assignReviewers(ctx, pr, pr.ReviewTeam(authorTeam))
+	if err != nil {
+		return err
+	}
+	if pr.TargetTeam == "" || !pr.AuthorTeamReview {
+		return nil
+	}
+	pr.RequiredReviewers++
+	return prs.addAuthorTeamReviewer(ctx, pr, authorTeam)
+}
+
+// addAuthorTeamReviewer добавляет ревьюера из команды автора стратегией этой команды.
+// Ревьюер необязательный, поэтому отсутствие свободных кандидатов ошибкой не считается
+func (prs *prService) addAuthorTeamReviewer(ctx context.Context, pr *models.PullRequest, authorTeam string) error {
+	settings, err := prs.teamRepo.GetTeamSettings(ctx, authorTeam)
+	if err != nil {
+		return err
+	}
+
+	filter := models.CandidateFilter{
+		TeamName:   authorTeam,
+		ExcludeIDs: append(pr.Authors(), pr.AssignedReviewers...),
+		Skills:     pr.Labels,
+	}
+	selected, _, err := prs.selectReviewers(ctx, settings.ReviewerStrategy, filter, 1)
+	if err != nil {
+		return err
+	}
+	pr.AssignedReviewers = append(pr.AssignedReviewers, selected...)
+	return nil
+}
+
+// assignReviewers подбирает ревьюеров пулл реквесту: владельца кода по CODEOWNERS и
+// участников команды teamName вместе с ее дополнительными пулами до max_reviewers, нехватку
+// закрывают резервные пулы команды. Недостающих до min_reviewers ищет эскалацией
+// по иерархии команд, если набрать их не удалось и там, возвращает CAPACITY_EXCEEDED, когда
+// кому-то из кандидатов помешал лимит нагрузки, иначе NO_CANDIDATE. Если лимит помешал
+// назначить хоть кого-то, нужен хотя бы один ревьюер и при min_reviewers = 0: пулл реквест
+// не создается без ревью только потому, что команда перегружена. Требуемым числом
+// ревьюеров пулл реквеста становится max_reviewers команды
+func (prs *prService) assignReviewers(ctx context.Context, pr *models.PullRequest, teamName string) error {
+	settings, err := prs.teamRepo.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	pr.RequiredReviewers = settings.MaxReviewers
+
+	exclude := append(pr.Authors(), pr.AssignedReviewers...)
+
+	ownerID, required, err := prs.pickCodeOwner(ctx, pr, teamName)
+	if err != nil {
+		return err
+	}
+	if ownerID != "" {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, ownerID)
+		pr.Assignments = append(pr.Assignments, models.ReviewerAssignment{
+			ReviewerID: ownerID,
+			Source:     models.SourceCodeOwner,
+		})
+		pr.CodeOwnerApprovalRequired = required
+		exclude = append(exclude, ownerID)
+	}
+
+	count := settings.MaxReviewers - len(pr.AssignedReviewers)
+	assignments, saturated, err := prs.staffFromTeam(ctx, pr, teamName, settings.ReviewerStrategy, exclude, count)
+	if err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, assignment.ReviewerID)
+		if assignment.Source != models.SourceTeam {
+			pr.Assignments = append(pr.Assignments, assignment)
+		}
+		exclude = append(exclude, assignment.ReviewerID)
+	}
+
+	minReviewers := settings.MinReviewers
+	if saturated && minReviewers < 1 {
+		minReviewers = 1
+	}
+	if len(pr.AssignedReviewers) < minReviewers {
+		escalated, escalationSaturated, err := prs.escalate(ctx, pr, teamName, exclude, minReviewers-len(pr.AssignedReviewers))
+		if err != nil {
+			return err
+		}
+		saturated = saturated || escalationSaturated
+		for _, reviewerID := range escalated {
+			pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+			pr.Assignments = append(pr.Assignments, models.ReviewerAssignment{
+				ReviewerID: reviewerID,
+				Source:     models.SourceEscalation,
+			})
+		}
+	}
+	if len(pr.AssignedReviewers) < minReviewers {
+		if saturated {
+			return errors.New(CAPACITY_EXCEEDED)
+		}
+		return errors.New(NO_CANDIDATE)
+	}
+	return nil
+}
+
+// staffFromTeam подбирает до count ревьюеров стратегией команды teamName среди ее участников
+// и дополнительных пулов, нехватку закрывают резервные пулы команды, в том числе когда
+// участники команды уперлись в лимит нагрузки. Вместе с ревьюерами возвращает признак того,
+// что кандидатов команды не хватило из-за лимита
+func (prs *prService) staffFromTeam(ctx context.Context, pr *models.PullRequest, teamName string, strategy models.ReviewerStrategy, exclude []string, count int) ([]models.ReviewerAssignment, bool, error) {
+	supplementary, fallback, err := prs.teamPools(ctx, teamName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	filter := models.CandidateFilter{
+		TeamName:   teamName,
+		Pools:      supplementary,
+		ExcludeIDs: exclude,
+		Skills:     pr.Labels,
+	}
+	reviewers, saturated, err := prs.selectReviewers(ctx, strategy, filter, count)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var assignments []models.ReviewerAssignment
+	for _, reviewerID := range reviewers {
+		assignments = append(assignments, models.ReviewerAssignment{ReviewerID: reviewerID, Source: models.SourceTeam})
+	}
+
+	exclude = append(append([]string{}, exclude...), reviewers...)
+	pooled, err := prs.selectFromPools(ctx, pr, fallback, exclude, count-len(reviewers))
+	if err != nil {
+		return nil, false, err
+	}
+	for _, reviewerID := range pooled {
+		assignments = append(assignments, models.ReviewerAssignment{ReviewerID: reviewerID, Source: models.SourcePool})
+	}
+	return assignments, saturated, nil
+}
+
+// pickCodeOwner выбирает наименее загруженного владельца измененных файлов.
+// CODEOWNERS репозитория имеет приоритет над CODEOWNERS команды автора
+func (prs *prService) pickCodeOwner(ctx context.Context, pr *models.PullRequest, teamName string) (string, bool, error) {
+	if len(pr.ChangedFiles) == 0 {
+		return "", false, nil
+	}
+
+	var file *models.CodeOwnersFile
+	var err error
+	if pr.Repository != "" {
+		file, err = prs.codeOwnersRepo.GetCodeOwners(ctx, models.CodeOwnersScopeRepository, pr.Repository)
+		if err != nil && !errors.Is(err, repos.ErrCodeOwnersNotFound) {
+			return "", false, err
+		}
+	}
+	if file == nil {
+		file, err = prs.codeOwnersRepo.GetCodeOwners(ctx, models.CodeOwnersScopeTeam, teamName)
+		if err != nil && !errors.Is(err, repos.ErrCodeOwnersNotFound) {
+			return "", false, err
+		}
+	}
+	if file == nil {
+		// CODEOWNERS не загружен ни для репозитория, ни для команды
+		return "", false, nil
+	}
+
+	codeOwners, err := ParseCodeOwners(file.Content)
+	if err != nil {
+		return "", false, err
+	}
+
+	var handles, teams []string
+	for _, path := range pr.ChangedFiles {
+		for _, owner := range codeOwners.Owners(path) {
+			if !strings.HasPrefix(owner, "@") {
+				// email-владельцев сопоставить не с чем
+				continue
+			}
+			owner = strings.TrimPrefix(owner, "@")
+			if idx := strings.Index(owner, "/"); idx >= 0 {
+				teams = append(teams, owner[idx+1:])
+			} else {
+				handles = append(handles, owner)
+			}
+		}
+	}
+	if len(handles) == 0 && len(teams) == 0 {
+		return "", false, nil
+	}
+
+	candidates, err := prs.userRepo.GetCodeOwnerCandidates(ctx, handles, teams, pr.Authors())
+	if err != nil {
+		return "", false, err
+	}
+	if len(candidates) == 0 {
+		if file.Required {
+			return "", false, errors.New(NO_CODEOWNER)
+		}
+		return "", false, nil
+	}
+	return candidates[0].UserID, file.Required, nil
+}
+
+// MergePR мерджит пулл реквест, если набрано нужное ревьюящей команде число аппрувов,
+// а при обязательном ревью CODEOWNERS - еще и аппрув владельца кода. force мерджит в обход проверок
+func (prs *prService) MergePR(ctx context.Context, prID string, force bool) (*models.PullRequest, error) {
 	pr, err := prs.prRepo.GetPRByID(ctx, prID)
 	if err != nil {
 		return nil, errors.New(NOT_FOUND)
@@ -82,9 +486,21 @@ func (prs *prService) MergePR(ctx context.Context, prID string) (*models.PullReq
 	if pr.Status == models.StatusMerged {
 		return pr, nil
 	}
+	if !pr.Status.CanTransitionTo(models.StatusMerged) {
+		return nil, errors.New(INVALID_TRANSITION)
+	}
+
+	if !force {
+		approved, err := prs.hasEnoughApprovals(ctx, pr)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			return nil, errors.New(NOT_ENOUGH_APPROVALS)
+		}
+	}
 
-	pr.Status = models.StatusMerged
-	updatedPR, err := prs.prRepo.UpdatePRStatus(ctx, prID, models.StatusMerged)
+	updatedPR, err := prs.prRepo.MergePR(ctx, prID, force)
 	if err != nil {
 		return nil, err
 	}
@@ -92,18 +508,78 @@ func (prs *prService) MergePR(ctx context.Context, prID string) (*models.PullReq
 	return updatedPR, nil
 }
 
-func (prs *prService) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (*models.PullRequest, string, error) {
-	if prID == "" || oldReviewerID == "" {
-		return nil, "", errors.New(INVALID_INPUT)
+// hasEnoughApprovals учитывает только последнее решение каждого назначенного ревьюера.
+// Число аппрувов задает команда, которая ревьюит пулл реквест
+func (prs *prService) hasEnoughApprovals(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return false, err
+	}
+	settings, err := prs.teamRepo.GetTeamSettings(ctx, pr.ReviewTeam(author.TeamName))
+	if err != nil {
+		return false, err
+	}
+	if settings.RequiredApprovals == 0 && !pr.CodeOwnerApprovalRequired {
+		return true, nil
 	}
 
-	pr, err := prs.prRepo.GetPRByID(ctx, prID)
+	reviews, err := prs.reviewRepo.GetLatestReviews(ctx, pr.ID)
 	if err != nil {
-		return nil, "", errors.New(NOT_FOUND)
+		return false, err
 	}
 
-	if pr.Status == models.StatusMerged {
-		return nil, "", errors.New(PR_MERGED)
+	approvals := 0
+	codeOwnerApproved := false
+	for _, review := range reviews {
+		if review.Decision != models.DecisionApproved {
+			continue
+		}
+		approvals++
+		if pr.SourceOf(review.ReviewerID) == models.SourceCodeOwner {
+			codeOwnerApproved = true
+		}
+	}
+
+	if pr.CodeOwnerApprovalRequired && !codeOwnerApproved {
+		return false, nil
+	}
+	return approvals >= settings.RequiredApprovals, nil
+}
+
+func (prs *prService) SubmitReview(ctx context.Context, review *models.Review) (*models.Review, error) {
+	if review.PRID == "" || review.ReviewerID == "" || !review.Decision.IsValid() {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	pr, err := prs.getOpenPR(ctx, review.PRID)
+	if err != nil {
+		return nil, err
+	}
+
+	isAssigned := false
+	for _, rev := range pr.AssignedReviewers {
+		if rev == review.ReviewerID {
+			isAssigned = true
+			break
+		}
+	}
+	if !isAssigned {
+		return nil, errors.New(NOT_ASSIGNED)
+	}
+
+	return prs.reviewRepo.CreateReview(ctx, review)
+}
+
+// ReassignReviewer заменяет ревьюера. Если newReviewerID не задан, замена выбирается стратегией
+// команды старого ревьюера, иначе указанный пользователь проверяется по тем же правилам
+func (prs *prService) ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (*models.PullRequest, string, error) {
+	if prID == "" || oldReviewerID == "" {
+		return nil, "", errors.New(INVALID_INPUT)
+	}
+
+	pr, err := prs.getOpenPR(ctx, prID)
+	if err != nil {
+		return nil, "", err
 	}
 
 	isAssigned := false
@@ -117,50 +593,616 @@ func (prs *prService) ReassignReviewer(ctx context.Context, prID, oldReviewerID
 		return nil, "", errors.New(NOT_ASSIGNED)
 	}
 
+	if newReviewerID == "" {
+		move, err := prs.reassign(ctx, pr, oldReviewerID, models.ReasonManual)
+		if err != nil {
+			return nil, "", err
+		}
+		newReviewerID = move.NewReviewerID
+	} else {
+		oldReviewer, err := prs.userRepo.GetUser(ctx, oldReviewerID)
+		if err != nil {
+			return nil, "", errors.New(NOT_FOUND)
+		}
+		author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			return nil, "", errors.New(NOT_FOUND)
+		}
+
+		allowedTeams := []string{oldReviewer.TeamName, author.TeamName}
+		if pr.TargetTeam != "" {
+			allowedTeams = append(allowedTeams, pr.TargetTeam)
+		}
+		err = prs.checkReviewer(ctx, pr, newReviewerID, allowedTeams)
+		if err != nil {
+			return nil, "", err
+		}
+		err = prs.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID, models.SourceManual, models.ReasonManual)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	updPR, err := prs.prRepo.GetPRByID(ctx, prID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return updPR, newReviewerID, nil
+}
+
+// reassign подбирает замену ревьюеру по правилам его команды и сохраняет причину замены
+func (prs *prService) reassign(ctx context.Context, pr *models.PullRequest, oldReviewerID string, reason models.ReassignReason) (*models.Reassignment, error) {
+	move, err := prs.pickReplacement(ctx, pr, oldReviewerID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	err = prs.prRepo.ReplaceReviewer(ctx, pr.ID, oldReviewerID, move.NewReviewerID, move.Source(), reason)
+	if err != nil {
+		return nil, err
+	}
+	return move, nil
+}
+
+// pickReplacement выбирает замену ревьюеру стратегией его команды с учетом ее дополнительных пулов,
+// ничего не сохраняя. Если кандидатов нет, замена ищется в резервных пулах команды,
+// затем эскалацией по иерархии команд
+func (prs *prService) pickReplacement(ctx context.Context, pr *models.PullRequest, oldReviewerID string, reason models.ReassignReason) (*models.Reassignment, error) {
 	oldReviewer, err := prs.userRepo.GetUser(ctx, oldReviewerID)
 	if err != nil {
-		return nil, "", errors.New(NOT_FOUND)
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	teamName, err := prs.reviewerTeam(ctx, pr, oldReviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	supplementary, fallback, err := prs.teamPools(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	// старый ревьюер входит в AssignedReviewers, поэтому тоже исключается
+	filter := models.CandidateFilter{
+		TeamName:   teamName,
+		Pools:      supplementary,
+		ExcludeIDs: append(pr.Authors(), pr.AssignedReviewers...),
+		Skills:     pr.Labels,
+	}
+	settings, err := prs.teamRepo.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	move := &models.Reassignment{
+		PRID:          pr.ID,
+		OldReviewerID: oldReviewerID,
+		Reason:        reason,
+	}
+	selected, saturated, err := prs.selectReviewers(ctx, settings.ReviewerStrategy, filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		selected, err = prs.selectFromPools(ctx, pr, fallback, filter.ExcludeIDs, 1)
+		if err != nil {
+			return nil, err
+		}
+		move.FromPool = len(selected) > 0
+	}
+	if len(selected) == 0 {
+		var escalationSaturated bool
+		selected, escalationSaturated, err = prs.escalate(ctx, pr, teamName, filter.ExcludeIDs, 1)
+		if err != nil {
+			return nil, err
+		}
+		saturated = saturated || escalationSaturated
+		move.Escalated = true
+	}
+	if len(selected) == 0 {
+		if saturated {
+			return nil, errors.New(CAPACITY_EXCEEDED)
+		}
+		return nil, errors.New(NO_CANDIDATE)
+	}
+	move.NewReviewerID = selected[0]
+	return move, nil
+}
+
+// reviewerTeam возвращает команду, за которую ревьюер назначен на пулл реквест: ревьюящую
+// команду или команду автора, если он состоит в них хотя бы дополнительно, иначе его основную команду
+func (prs *prService) reviewerTeam(ctx context.Context, pr *models.PullRequest, reviewer *models.User) (string, error) {
+	author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return "", errors.New(NOT_FOUND)
+	}
+	teams, err := prs.userRepo.GetUserTeams(ctx, reviewer.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, team := range []string{pr.ReviewTeam(author.TeamName), author.TeamName} {
+		if team != "" && slices.Contains(teams, team) {
+			return team, nil
+		}
+	}
+	return reviewer.TeamName, nil
+}
+
+// PlanReassignments подбирает замену ревьюеру во всех его OPEN пулл реквестах, ничего не сохраняя.
+// План строится на рабочей копии нагрузки: каждое розданное ревью учитывается при выборе следующих
+// замен, так что лимит кандидатов не превышается. Позиции ротации команд возвращаются в отчете
+// и сохраняются вместе с переназначениями. Пулл реквесты, которым не нашлось замены,
+// возвращаются отдельным списком
+func (prs *prService) PlanReassignments(ctx context.Context, reviewerID string, reason models.ReassignReason) (*models.ReassignmentReport, error) {
+	reviews, err := prs.prRepo.GetPRsByReviewer(ctx, reviewerID, []models.Status{models.StatusOpen})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := newReassignmentPlan()
+	planner := *prs
+	planner.selectors = planSelectors(prs.userRepo, prs.teamRepo, plan)
+
+	report := &models.ReassignmentReport{
+		Reassigned:  []models.Reassignment{},
+		NoCandidate: []string{},
+		Rotation:    plan.rotation,
 	}
+	for _, review := range reviews {
+		pr, err := prs.prRepo.GetPRByID(ctx, review.ID)
+		if err != nil {
+			return nil, err
+		}
+		move, err := planner.pickReplacement(ctx, pr, reviewerID, reason)
+		if err != nil {
+			if err.Error() == NO_CANDIDATE || err.Error() == CAPACITY_EXCEEDED {
+				report.NoCandidate = append(report.NoCandidate, pr.ID)
+				continue
+			}
+			return nil, err
+		}
+		plan.load[move.NewReviewerID]++
+		report.Reassigned = append(report.Reassigned, *move)
+	}
+	return report, nil
+}
 
-	candidates, err := prs.userRepo.GetActiveUsersByTeam(ctx, oldReviewer.TeamName)
+// ReassignStaleReviews переназначает ревьюеров, не принявших решение за SLA команды, которая ревьюит пулл реквест.
+// Назначения, которым не нашлось замены, остаются на месте и проверяются снова при следующем запуске
+func (prs *prService) ReassignStaleReviews(ctx context.Context, now time.Time) ([]models.Reassignment, error) {
+	pending, err := prs.prRepo.GetPendingReviews(ctx)
 	if err != nil {
-		return nil, "", errors.New(NO_CANDIDATE)
+		return nil, err
 	}
 
-	var available []string
-	for _, user := range candidates {
-		if user.ID == oldReviewerID || user.ID == pr.AuthorID {
+	var reassigned []models.Reassignment
+	settingsByTeam := make(map[string]*models.TeamSettings)
+	for _, review := range pending {
+		settings, ok := settingsByTeam[review.TeamName]
+		if !ok {
+			settings, err = prs.teamRepo.GetTeamSettings(ctx, review.TeamName)
+			if err != nil {
+				return reassigned, err
+			}
+			settingsByTeam[review.TeamName] = settings
+		}
+		if !SLAExpired(review.AssignedAt, now, settings) {
 			continue
 		}
 
-		// на всякий случай
-		isAlreadyAssigned := false
-		for _, assigned := range pr.AssignedReviewers {
-			if assigned == user.ID && assigned != oldReviewerID {
-				isAlreadyAssigned = true
-				break
+		pr, err := prs.prRepo.GetPRByID(ctx, review.PRID)
+		if err != nil {
+			return reassigned, err
+		}
+		move, err := prs.reassign(ctx, pr, review.ReviewerID, models.ReasonSLAExpired)
+		if err != nil {
+			if err.Error() == NO_CANDIDATE || err.Error() == CAPACITY_EXCEEDED {
+				continue
 			}
+			return reassigned, err
 		}
-		if !isAlreadyAssigned {
-			available = append(available, user.ID)
+		reassigned = append(reassigned, *move)
+	}
+	return reassigned, nil
+}
+
+// getOpenPR возвращает пулл реквест в статусе OPEN - только у него меняются ревьюеры и ревью
+func (prs *prService) getOpenPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	pr, err := prs.prRepo.GetPRByID(ctx, prID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	switch pr.Status {
+	case models.StatusMerged:
+		return nil, errors.New(PR_MERGED)
+	case models.StatusClosed:
+		return nil, errors.New(PR_CLOSED)
+	case models.StatusDraft:
+		return nil, errors.New(PR_DRAFT)
+	}
+	return pr, nil
+}
+
+// checkReviewer проверяет, что пользователя можно вручную назначить ревьюером пулл реквеста
+// из одной из allowedTeams, по тем же правилам отсутствия и лимита нагрузки, что и при
+// автоматическом подборе, и возвращает код первого нарушенного правила
+func (prs *prService) checkReviewer(ctx context.Context, pr *models.PullRequest, userID string, allowedTeams []string) error {
+	if slices.Contains(pr.Authors(), userID) {
+		return errors.New(REVIEWER_IS_AUTHOR)
+	}
+
+	user, err := prs.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return errors.New(NOT_FOUND)
+	}
+	if !user.IsActive {
+		return errors.New(USER_INACTIVE)
+	}
+
+	for _, rev := range pr.AssignedReviewers {
+		if rev == userID {
+			return errors.New(ALREADY_ASSIGNED)
 		}
 	}
 
-	if len(available) == 0 {
-		return nil, "", errors.New(NO_CANDIDATE)
+	teams, err := prs.userRepo.GetUserTeams(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(allowedTeams, func(team string) bool {
+		return slices.Contains(teams, team)
+	}) {
+		return errors.New(NOT_TEAM_MEMBER)
 	}
 
-	newReviewerID := available[rand.Intn(len(available))]
+	availability, err := prs.userRepo.GetReviewerAvailability(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if availability.Unavailable {
+		return errors.New(USER_UNAVAILABLE)
+	}
+	if availability.AtCapacity {
+		return errors.New(REVIEWER_AT_CAPACITY)
+	}
+	return nil
+}
+
+// AddReviewer добавляет ревьюера на пулл реквест: явно указанного или, при user_id = "auto",
+// выбранного стратегией команды, которая ревьюит пулл реквест
+func (prs *prService) AddReviewer(ctx context.Context, req *models.ReviewerChangeRequest) (*models.PullRequest, error) {
+	if req.PRID == "" || req.UserID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
 
-	err = prs.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID)
+	pr, err := prs.getOpenPR(ctx, req.PRID)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	updPR, err := prs.prRepo.GetPRByID(ctx, prID)
+	author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
 	if err != nil {
-		return nil, "", err
+		return nil, errors.New(NOT_FOUND)
 	}
 
-	return updPR, newReviewerID, nil
+	reviewTeam := pr.ReviewTeam(author.TeamName)
+	reviewerID := req.UserID
+	source := models.SourceManual
+	if reviewerID == models.AutoReviewer {
+		settings, err := prs.teamRepo.GetTeamSettings(ctx, reviewTeam)
+		if err != nil {
+			return nil, err
+		}
+
+		filter := models.CandidateFilter{
+			TeamName:   reviewTeam,
+			ExcludeIDs: append(pr.Authors(), pr.AssignedReviewers...),
+			Skills:     pr.Labels,
+		}
+		selected, saturated, err := prs.selectReviewers(ctx, settings.ReviewerStrategy, filter, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(selected) == 0 {
+			if saturated {
+				return nil, errors.New(CAPACITY_EXCEEDED)
+			}
+			return nil, errors.New(NO_CANDIDATE)
+		}
+		reviewerID = selected[0]
+		source = models.SourceTeam
+	} else if err := prs.checkReviewer(ctx, pr, reviewerID, slices.Compact([]string{reviewTeam, author.TeamName})); err != nil {
+		return nil, err
+	}
+
+	err = prs.prRepo.AddReviewer(ctx, pr.ID, reviewerID, source)
+	if err != nil {
+		return nil, err
+	}
+	return prs.prRepo.GetPRByID(ctx, pr.ID)
+}
+
+// RemoveReviewer снимает ревьюера с пулл реквеста, если после этого останется
+// не меньше min_reviewers команды, которая ревьюит пулл реквест
+func (prs *prService) RemoveReviewer(ctx context.Context, req *models.ReviewerChangeRequest) (*models.PullRequest, error) {
+	if req.PRID == "" || req.UserID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	pr, err := prs.getOpenPR(ctx, req.PRID)
+	if err != nil {
+		return nil, err
+	}
+
+	isAssigned := false
+	for _, rev := range pr.AssignedReviewers {
+		if rev == req.UserID {
+			isAssigned = true
+			break
+		}
+	}
+	if !isAssigned {
+		return nil, errors.New(NOT_ASSIGNED)
+	}
+
+	author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	settings, err := prs.teamRepo.GetTeamSettings(ctx, pr.ReviewTeam(author.TeamName))
+	if err != nil {
+		return nil, err
+	}
+	if len(pr.AssignedReviewers)-1 < settings.MinReviewers {
+		return nil, errors.New(BELOW_MIN_REVIEWERS)
+	}
+
+	err = prs.prRepo.RemoveReviewer(ctx, pr.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return prs.prRepo.GetPRByID(ctx, pr.ID)
+}
+
+// transition переводит пулл реквест в статус to, если переход разрешен жизненным циклом
+// и текущий статус входит в from. Ревьюеры назначаются, когда пулл реквест становится OPEN,
+// а у него еще нет ни одного ревьюера
+func (prs *prService) transition(ctx context.Context, prID string, to models.Status, from ...models.Status) (*models.PullRequest, error) {
+	if prID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	pr, err := prs.prRepo.GetPRByID(ctx, prID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	if pr.Status == to {
+		return pr, nil
+	}
+	if pr.Status == models.StatusMerged {
+		return nil, errors.New(PR_MERGED)
+	}
+	if !pr.Status.CanTransitionTo(to) || !slices.Contains(from, pr.Status) {
+		return nil, errors.New(INVALID_TRANSITION)
+	}
+
+	var reviewers []models.ReviewerAssignment
+	requiredReviewers := 0
+	if to == models.StatusOpen && len(pr.AssignedReviewers) == 0 {
+		author, err := prs.userRepo.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			return nil, errors.New(NOT_FOUND)
+		}
+		err = prs.assignPRReviewers(ctx, pr, author.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		reviewers = pr.ReviewerAssignments()
+		requiredReviewers = pr.RequiredReviewers
+	}
+
+	return prs.prRepo.TransitionPR(ctx, prID, pr.Status, to, reviewers, requiredReviewers)
+}
+
+func (prs *prService) ClosePR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return prs.transition(ctx, prID, models.StatusClosed, models.StatusDraft, models.StatusOpen)
+}
+
+func (prs *prService) ReopenPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return prs.transition(ctx, prID, models.StatusOpen, models.StatusClosed)
+}
+
+func (prs *prService) MarkReady(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return prs.transition(ctx, prID, models.StatusOpen, models.StatusDraft)
+}
+
+func (prs *prService) GetPR(ctx context.Context, prID string) (*models.PullRequest, error) {
+	if prID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	pr, err := prs.prRepo.GetPRByID(ctx, prID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return pr, nil
+}
+
+// ListPRs возвращает страницу пулл реквестов. cursor - значение next_cursor предыдущей
+// страницы, он действителен только для той же сортировки
+func (prs *prService) ListPRs(ctx context.Context, filter models.PRListFilter, cursor string) (*models.PRPage, error) {
+	for _, status := range filter.Statuses {
+		if !status.IsValid() {
+			return nil, errors.New(INVALID_INPUT)
+		}
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = models.SortByCreatedAt
+	}
+	if !filter.SortBy.IsValid() || filter.Limit < 0 {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = models.DefaultPRPageSize
+	}
+	filter.Limit = min(filter.Limit, models.MaxPRPageSize)
+
+	// в бд время хранится без часового пояса в UTC
+	for _, t := range []**time.Time{&filter.CreatedFrom, &filter.CreatedTo, &filter.MergedFrom, &filter.MergedTo} {
+		if *t != nil {
+			utc := (*t).UTC()
+			*t = &utc
+		}
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if filter.MergedFrom != nil && filter.MergedTo != nil && filter.MergedFrom.After(*filter.MergedTo) {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	if cursor != "" {
+		after, err := DecodePRCursor(cursor, filter.SortBy, filter.Desc)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// лишняя строка показывает, есть ли следующая страница
+	pageSize := filter.Limit
+	filter.Limit++
+	list, err := prs.prRepo.ListPRs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PRPage{
+		PullRequests: list,
+	}
+	if len(list) > pageSize {
+		page.PullRequests = list[:pageSize]
+		page.NextCursor = EncodePRCursor(&page.PullRequests[pageSize-1], filter.SortBy, filter.Desc)
+	}
+	if page.PullRequests == nil {
+		page.PullRequests = []models.PullRequest{}
+	}
+	return page, nil
+}
+
+// ListUnderstaffed возвращает OPEN пулл реквесты, которым все еще не хватает ревьюеров.
+// Непустой teamName оставляет только те, ревьюеров которым подбирает эта команда
+func (prs *prService) ListUnderstaffed(ctx context.Context, teamName string) ([]models.UnderstaffedPR, error) {
+	var teamNames []string
+	if teamName != "" {
+		exists, err := prs.teamRepo.IsTeamExists(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		if !*exists {
+			return nil, errors.New(NOT_FOUND)
+		}
+		teamNames = []string{teamName}
+	}
+
+	understaffed, err := prs.prRepo.GetUnderstaffedPRs(ctx, teamNames)
+	if err != nil {
+		return nil, err
+	}
+	if understaffed == nil {
+		understaffed = []models.UnderstaffedPR{}
+	}
+	return understaffed, nil
+}
+
+// BackfillReviewers добирает ревьюеров OPEN пулл реквестам команд teamNames, которым их не хватает,
+// по тем же правилам, что и при создании, кроме эскалации. Пулл реквесты, для которых кандидатов
+// по-прежнему нет, остаются как есть до следующего пополнения команды
+func (prs *prService) BackfillReviewers(ctx context.Context, teamNames []string) ([]models.ReviewerBackfill, error) {
+	understaffed, err := prs.prRepo.GetUnderstaffedPRs(ctx, teamNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var backfilled []models.ReviewerBackfill
+	for _, short := range understaffed {
+		pr, err := prs.prRepo.GetPRByID(ctx, short.ID)
+		if err != nil {
+			return backfilled, err
+		}
+		var authorTeam string
+		if author, err := prs.userRepo.GetUser(ctx, pr.AuthorID); err == nil {
+			authorTeam = author.TeamName
+		}
+		if pr.ReviewTeam(authorTeam) == "" {
+			continue
+		}
+
+		added, err := prs.topUpReviewers(ctx, pr, authorTeam)
+		if err != nil {
+			return backfilled, err
+		}
+		for _, assignment := range added {
+			err := prs.prRepo.AddReviewer(ctx, pr.ID, assignment.ReviewerID, assignment.Source)
+			if err != nil {
+				return backfilled, err
+			}
+		}
+		if len(added) > 0 {
+			backfilled = append(backfilled, models.ReviewerBackfill{PRID: pr.ID, Reviewers: added})
+		}
+	}
+	return backfilled, nil
+}
+
+// topUpReviewers подбирает недостающих до required_reviewers ревьюеров, ничего не сохраняя.
+// Место запрошенного ревьюера из команды автора остается за ней, остальные места заполняет
+// ревьюящая команда. Упершаяся в лимит нагрузки команда просто не дает кандидатов
+func (prs *prService) topUpReviewers(ctx context.Context, pr *models.PullRequest, authorTeam string) ([]models.ReviewerAssignment, error) {
+	missing := pr.RequiredReviewers - len(pr.AssignedReviewers)
+	if missing <= 0 {
+		return nil, nil
+	}
+
+	authorSlot := false
+	if pr.TargetTeam != "" && pr.AuthorTeamReview && authorTeam != "" {
+		members, err := prs.userRepo.FilterTeamMembers(ctx, authorTeam, pr.AssignedReviewers)
+		if err != nil {
+			return nil, err
+		}
+		authorSlot = len(members) == 0
+		if authorSlot {
+			missing--
+		}
+	}
+
+	var added []models.ReviewerAssignment
+	if missing > 0 {
+		reviewTeam := pr.ReviewTeam(authorTeam)
+		settings, err := prs.teamRepo.GetTeamSettings(ctx, reviewTeam)
+		if err != nil {
+			return nil, err
+		}
+		exclude := append(pr.Authors(), pr.AssignedReviewers...)
+		added, _, err = prs.staffFromTeam(ctx, pr, reviewTeam, settings.ReviewerStrategy, exclude, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range added {
+			pr.AssignedReviewers = append(pr.AssignedReviewers, assignment.ReviewerID)
+		}
+	}
+
+	if authorSlot {
+		before := len(pr.AssignedReviewers)
+		if err := prs.addAuthorTeamReviewer(ctx, pr, authorTeam); err != nil {
+			return nil, err
+		}
+		for _, reviewerID := range pr.AssignedReviewers[before:] {
+			added = append(added, models.ReviewerAssignment{ReviewerID: reviewerID, Source: models.SourceTeam})
+		}
+	}
+	return added, nil
 }
@@ -0,0 +1,218 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubPRRepo struct {
+	repos.PRRepo
+	prs     map[string]*models.PullRequest
+	created *models.PullRequest
+}
+
+func (r *stubPRRepo) GetPRByID(ctx context.Context, prID string) (*models.PullRequest, error) {
+	pr, ok := r.prs[prID]
+	if !ok {
+		return nil, errors.New("пулл реквест не найден")
+	}
+	return pr, nil
+}
+
+func (r *stubPRRepo) GetPRsByReviewer(ctx context.Context, userID string, statuses []models.Status) ([]models.PullRequestShort, error) {
+	var reviews []models.PullRequestShort
+	for _, pr := range r.prs {
+		if slices.Contains(pr.AssignedReviewers, userID) {
+			reviews = append(reviews, models.PullRequestShort{ID: pr.ID})
+		}
+	}
+	slices.SortFunc(reviews, func(a, b models.PullRequestShort) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return reviews, nil
+}
+
+func (r *stubPRRepo) CreatePR(ctx context.Context, pr *models.PullRequest) error {
+	r.created = pr
+	return nil
+}
+
+// stubUserRepo - команда backend из candidates, достигшие лимита отдаются только при IgnoreCapacity
+type stubUserRepo struct {
+	repos.UserRepo
+	candidates []models.ReviewCandidate
+}
+
+func (r *stubUserRepo) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	return &models.User{ID: userID, Username: userID, TeamName: "backend", IsActive: true}, nil
+}
+
+func (r *stubUserRepo) GetUserTeams(ctx context.Context, userID string) ([]string, error) {
+	return []string{"backend"}, nil
+}
+
+func (r *stubUserRepo) GetReviewCandidates(ctx context.Context, filter models.CandidateFilter) ([]models.ReviewCandidate, error) {
+	var candidates []models.ReviewCandidate
+	for _, candidate := range r.candidates {
+		if slices.Contains(filter.ExcludeIDs, candidate.UserID) {
+			continue
+		}
+		if !filter.IgnoreCapacity && candidate.MaxOpenReviews > 0 && candidate.OpenReviews >= candidate.MaxOpenReviews {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+type stubTeamRepo struct {
+	repos.TeamRepo
+	strategy models.ReviewerStrategy
+	rotated  []string
+}
+
+func (r *stubTeamRepo) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	settings := models.DefaultTeamSettings(teamName)
+	if r.strategy != "" {
+		settings.ReviewerStrategy = r.strategy
+	}
+	return settings, nil
+}
+
+func (r *stubTeamRepo) GetTeamAncestors(ctx context.Context, teamName string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *stubTeamRepo) GetLastRotatedReviewer(ctx context.Context, teamName string) (string, error) {
+	return "", nil
+}
+
+func (r *stubTeamRepo) SetLastRotatedReviewer(ctx context.Context, teamName, userID string) error {
+	r.rotated = append(r.rotated, userID)
+	return nil
+}
+
+type stubPoolRepo struct {
+	repos.ReviewerPoolRepo
+}
+
+func (r *stubPoolRepo) GetTeamPools(ctx context.Context, teamName string) ([]models.TeamPool, error) {
+	return nil, nil
+}
+
+func TestPRService_CreatePR(t *testing.T) {
+	newService := func(prRepo *stubPRRepo, userRepo *stubUserRepo) services.PRService {
+		return services.NewPRService(prRepo, userRepo, &stubTeamRepo{}, nil, nil, &stubPoolRepo{})
+	}
+	req := &models.CreatePRRequest{ID: "pr1", Name: "feature", AuthorID: "u1"}
+
+	t.Run("перегруженная команда при настройках по умолчанию", func(t *testing.T) {
+		prRepo := &stubPRRepo{}
+		userRepo := &stubUserRepo{candidates: []models.ReviewCandidate{
+			{UserID: "u2", OpenReviews: 1, MaxOpenReviews: 1},
+			{UserID: "u3", OpenReviews: 3, MaxOpenReviews: 3},
+		}}
+
+		pr, err := newService(prRepo, userRepo).CreatePR(context.Background(), req)
+
+		assert.EqualError(t, err, services.CAPACITY_EXCEEDED)
+		assert.Nil(t, pr)
+		assert.Nil(t, prRepo.created)
+	})
+
+	t.Run("часть команды уперлась в лимит", func(t *testing.T) {
+		prRepo := &stubPRRepo{}
+		userRepo := &stubUserRepo{candidates: []models.ReviewCandidate{
+			{UserID: "u2"},
+			{UserID: "u3", OpenReviews: 1, MaxOpenReviews: 1},
+		}}
+
+		pr, err := newService(prRepo, userRepo).CreatePR(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		assert.Equal(t, 2, pr.RequiredReviewers)
+	})
+
+	t.Run("в команде нет других участников", func(t *testing.T) {
+		prRepo := &stubPRRepo{}
+		userRepo := &stubUserRepo{}
+
+		pr, err := newService(prRepo, userRepo).CreatePR(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+		assert.Same(t, pr, prRepo.created)
+	})
+}
+
+func TestPRService_PlanReassignments(t *testing.T) {
+	openPRs := func(ids ...string) map[string]*models.PullRequest {
+		prs := make(map[string]*models.PullRequest, len(ids))
+		for _, id := range ids {
+			prs[id] = &models.PullRequest{ID: id, AuthorID: "u9", Status: models.StatusOpen, AssignedReviewers: []string{"u1"}}
+		}
+		return prs
+	}
+	newReviewers := func(report *models.ReassignmentReport) []string {
+		var ids []string
+		for _, move := range report.Reassigned {
+			ids = append(ids, move.NewReviewerID)
+		}
+		return ids
+	}
+
+	t.Run("лимит учитывает уже розданные ревью", func(t *testing.T) {
+		teamRepo := &stubTeamRepo{strategy: models.StrategyRoundRobin}
+		userRepo := &stubUserRepo{candidates: []models.ReviewCandidate{
+			{UserID: "u2", MaxOpenReviews: 1},
+			{UserID: "u3", MaxOpenReviews: 1},
+		}}
+		prService := services.NewPRService(&stubPRRepo{prs: openPRs("pr1", "pr2", "pr3")}, userRepo, teamRepo, nil, nil, &stubPoolRepo{})
+
+		report, err := prService.PlanReassignments(context.Background(), "u1", models.ReasonDeactivated)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u2", "u3"}, newReviewers(report))
+		assert.Equal(t, []string{"pr3"}, report.NoCandidate)
+	})
+
+	t.Run("ротация сохраняется вместе с переназначениями", func(t *testing.T) {
+		teamRepo := &stubTeamRepo{strategy: models.StrategyRoundRobin}
+		userRepo := &stubUserRepo{candidates: []models.ReviewCandidate{
+			{UserID: "u2"},
+			{UserID: "u3"},
+		}}
+		prService := services.NewPRService(&stubPRRepo{prs: openPRs("pr1", "pr2", "pr3")}, userRepo, teamRepo, nil, nil, &stubPoolRepo{})
+
+		report, err := prService.PlanReassignments(context.Background(), "u1", models.ReasonDeactivated)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u2", "u3", "u2"}, newReviewers(report))
+		assert.Equal(t, map[string]string{"backend": "u2"}, report.Rotation)
+		assert.Empty(t, teamRepo.rotated)
+	})
+
+	t.Run("наименее загруженный с учетом плана", func(t *testing.T) {
+		teamRepo := &stubTeamRepo{strategy: models.StrategyLeastLoaded}
+		userRepo := &stubUserRepo{candidates: []models.ReviewCandidate{
+			{UserID: "u2", OpenReviews: 0},
+			{UserID: "u3", OpenReviews: 1},
+		}}
+		prService := services.NewPRService(&stubPRRepo{prs: openPRs("pr1", "pr2", "pr3")}, userRepo, teamRepo, nil, nil, &stubPoolRepo{})
+
+		report, err := prService.PlanReassignments(context.Background(), "u1", models.ReasonDeactivated)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"u2", "u2", "u3"}, newReviewers(report))
+		assert.Empty(t, report.NoCandidate)
+	})
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+// BalanceReassignments распределяет назначения уходящих ревьюеров между кандидатами по нагрузке:
+// каждое назначение получает наименее загруженный подходящий кандидат, при равной нагрузке
+// предпочтение отдается совпадению навыков с метками пулл реквеста. Кандидат подходит,
+// если он не автор или соавтор, еще не ревьюер этого пулл реквеста и не достиг лимита OPEN ревью
+func BalanceReassignments(assignments []models.OpenAssignment, candidates []models.ReviewCandidate, reason models.ReassignReason) *models.ReassignmentReport {
+	report := &models.ReassignmentReport{
+		Reassigned:  []models.Reassignment{},
+		NoCandidate: []string{},
+	}
+
+	load := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		load[i] = candidate.OpenReviews
+	}
+
+	reviewersByPR := make(map[string]map[string]bool)
+	noCandidate := make(map[string]bool)
+	for _, assignment := range assignments {
+		reviewers, ok := reviewersByPR[assignment.PRID]
+		if !ok {
+			reviewers = make(map[string]bool, len(assignment.Reviewers))
+			for _, id := range assignment.Reviewers {
+				reviewers[id] = true
+			}
+			reviewersByPR[assignment.PRID] = reviewers
+		}
+
+		best := -1
+		bestMatches := false
+		for i, candidate := range candidates {
+			if candidate.UserID == assignment.AuthorID || slices.Contains(assignment.CoAuthors, candidate.UserID) || reviewers[candidate.UserID] {
+				continue
+			}
+			if candidate.MaxOpenReviews > 0 && load[i] >= candidate.MaxOpenReviews {
+				continue
+			}
+			matches := hasAnyTag(candidate.Skills, assignment.Labels)
+			if best == -1 || load[i] < load[best] || (load[i] == load[best] && matches && !bestMatches) {
+				best = i
+				bestMatches = matches
+			}
+		}
+
+		if best == -1 {
+			if !noCandidate[assignment.PRID] {
+				noCandidate[assignment.PRID] = true
+				report.NoCandidate = append(report.NoCandidate, assignment.PRID)
+			}
+			continue
+		}
+
+		newReviewerID := candidates[best].UserID
+		load[best]++
+		delete(reviewers, assignment.ReviewerID)
+		reviewers[newReviewerID] = true
+		report.Reassigned = append(report.Reassigned, models.Reassignment{
+			PRID:          assignment.PRID,
+			OldReviewerID: assignment.ReviewerID,
+			NewReviewerID: newReviewerID,
+			Reason:        reason,
+		})
+	}
+	return report
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
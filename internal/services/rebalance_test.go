@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceReassignments(t *testing.T) {
+	t.Run("распределение по нагрузке", func(t *testing.T) {
+		candidates := []models.ReviewCandidate{
+			{UserID: "u3", OpenReviews: 2},
+			{UserID: "u4", OpenReviews: 0},
+		}
+		assignments := []models.OpenAssignment{
+			{PRID: "pr1", ReviewerID: "u1", AuthorID: "u9", Reviewers: []string{"u1"}},
+			{PRID: "pr2", ReviewerID: "u1", AuthorID: "u9", Reviewers: []string{"u1"}},
+			{PRID: "pr3", ReviewerID: "u1", AuthorID: "u9", Reviewers: []string{"u1"}},
+		}
+
+		report := services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+
+		var got []string
+		for _, r := range report.Reassigned {
+			got = append(got, r.NewReviewerID)
+		}
+		assert.Equal(t, []string{"u4", "u4", "u3"}, got)
+		assert.Empty(t, report.NoCandidate)
+	})
+
+	t.Run("автор, уже назначенные и лимит исключаются", func(t *testing.T) {
+		candidates := []models.ReviewCandidate{
+			{UserID: "u3", OpenReviews: 0},
+			{UserID: "u4", OpenReviews: 0},
+			{UserID: "u5", OpenReviews: 1, MaxOpenReviews: 1},
+		}
+		assignments := []models.OpenAssignment{
+			{PRID: "pr1", ReviewerID: "u1", AuthorID: "u3", Reviewers: []string{"u1", "u4"}},
+		}
+
+		report := services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+
+		assert.Empty(t, report.Reassigned)
+		assert.Equal(t, []string{"pr1"}, report.NoCandidate)
+	})
+
+	t.Run("соавторы исключаются", func(t *testing.T) {
+		candidates := []models.ReviewCandidate{
+			{UserID: "u3", OpenReviews: 0},
+			{UserID: "u4", OpenReviews: 2},
+		}
+		assignments := []models.OpenAssignment{
+			{PRID: "pr1", ReviewerID: "u1", AuthorID: "u2", CoAuthors: []string{"u3"}, Reviewers: []string{"u1"}},
+		}
+
+		report := services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+
+		assert.Len(t, report.Reassigned, 1)
+		assert.Equal(t, "u4", report.Reassigned[0].NewReviewerID)
+	})
+
+	t.Run("два уходящих ревьюера одного пулл реквеста не получают одну замену", func(t *testing.T) {
+		candidates := []models.ReviewCandidate{
+			{UserID: "u3", OpenReviews: 0},
+			{UserID: "u4", OpenReviews: 5},
+		}
+		assignments := []models.OpenAssignment{
+			{PRID: "pr1", ReviewerID: "u1", AuthorID: "u9", Reviewers: []string{"u1", "u2"}},
+			{PRID: "pr1", ReviewerID: "u2", AuthorID: "u9", Reviewers: []string{"u1", "u2"}},
+		}
+
+		report := services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+
+		assert.Len(t, report.Reassigned, 2)
+		assert.Equal(t, "u3", report.Reassigned[0].NewReviewerID)
+		assert.Equal(t, "u4", report.Reassigned[1].NewReviewerID)
+	})
+
+	t.Run("при равной нагрузке предпочитаются совпавшие навыки", func(t *testing.T) {
+		candidates := []models.ReviewCandidate{
+			{UserID: "u3", OpenReviews: 1},
+			{UserID: "u4", OpenReviews: 1, Skills: []string{"go"}},
+		}
+		assignments := []models.OpenAssignment{
+			{PRID: "pr1", ReviewerID: "u1", AuthorID: "u9", Labels: []string{"go"}, Reviewers: []string{"u1"}},
+		}
+
+		report := services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+
+		assert.Equal(t, "u4", report.Reassigned[0].NewReviewerID)
+	})
+}
+
+func BenchmarkBalanceReassignments(b *testing.B) {
+	candidates := make([]models.ReviewCandidate, 300)
+	for i := range candidates {
+		candidates[i] = models.ReviewCandidate{UserID: fmt.Sprintf("u%d", i), OpenReviews: i % 7}
+	}
+	assignments := make([]models.OpenAssignment, 5000)
+	for i := range assignments {
+		assignments[i] = models.OpenAssignment{
+			PRID:       fmt.Sprintf("pr%d", i),
+			ReviewerID: "leaving",
+			AuthorID:   fmt.Sprintf("u%d", i%300),
+			Reviewers:  []string{"leaving"},
+		}
+	}
+
+	for i := 0; i < b.N; i++ {
+		services.BalanceReassignments(assignments, candidates, models.ReasonReorg)
+	}
+}
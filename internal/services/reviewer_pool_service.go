@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+)
+
+type ReviewerPoolService interface {
+	SavePool(ctx context.Context, req *models.SavePoolRequest) (*models.ReviewerPool, error)
+	GetPool(ctx context.Context, poolName string) (*models.ReviewerPool, error)
+	ListPools(ctx context.Context) ([]models.ReviewerPool, error)
+	DeletePool(ctx context.Context, poolName string) error
+	AttachPool(ctx context.Context, teamPool *models.TeamPool) ([]models.TeamPool, error)
+	DetachPool(ctx context.Context, teamName, poolName string) ([]models.TeamPool, error)
+	GetTeamPools(ctx context.Context, teamName string) ([]models.TeamPool, error)
+}
+
+type reviewerPoolService struct {
+	poolRepo repos.ReviewerPoolRepo
+	userRepo repos.UserRepo
+	teamRepo repos.TeamRepo
+}
+
+func NewReviewerPoolService(poolRepo repos.ReviewerPoolRepo, userRepo repos.UserRepo, teamRepo repos.TeamRepo) ReviewerPoolService {
+	return &reviewerPoolService{
+		poolRepo: poolRepo,
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+	}
+}
+
+// SavePool создает пул или заменяет его состав. В пул можно включить пользователей из любых команд
+func (rps *reviewerPoolService) SavePool(ctx context.Context, req *models.SavePoolRequest) (*models.ReviewerPool, error) {
+	if req.Name == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	userIDs := make([]string, 0, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		if userID == "" {
+			return nil, errors.New(INVALID_INPUT)
+		}
+		if !slices.Contains(userIDs, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	users, err := rps.userRepo.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != len(userIDs) {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	err = rps.poolRepo.SavePool(ctx, req.Name, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return rps.poolRepo.GetPool(ctx, req.Name)
+}
+
+func (rps *reviewerPoolService) GetPool(ctx context.Context, poolName string) (*models.ReviewerPool, error) {
+	if poolName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	pool, err := rps.poolRepo.GetPool(ctx, poolName)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return pool, nil
+}
+
+func (rps *reviewerPoolService) ListPools(ctx context.Context) ([]models.ReviewerPool, error) {
+	pools, err := rps.poolRepo.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pools == nil {
+		pools = []models.ReviewerPool{}
+	}
+	return pools, nil
+}
+
+// DeletePool удаляет пул и отключает его от всех команд. Уже назначенные из пула ревьюеры остаются
+func (rps *reviewerPoolService) DeletePool(ctx context.Context, poolName string) error {
+	if _, err := rps.GetPool(ctx, poolName); err != nil {
+		return err
+	}
+	return rps.poolRepo.DeletePool(ctx, poolName)
+}
+
+// AttachPool подключает пул к команде или меняет режим уже подключенного пула
+func (rps *reviewerPoolService) AttachPool(ctx context.Context, teamPool *models.TeamPool) ([]models.TeamPool, error) {
+	if teamPool.TeamName == "" || teamPool.PoolName == "" || !teamPool.Mode.IsValid() {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if err := rps.ensureTeamExists(ctx, teamPool.TeamName); err != nil {
+		return nil, err
+	}
+	if _, err := rps.GetPool(ctx, teamPool.PoolName); err != nil {
+		return nil, err
+	}
+
+	err := rps.poolRepo.SetTeamPool(ctx, teamPool)
+	if err != nil {
+		return nil, err
+	}
+	return rps.poolRepo.GetTeamPools(ctx, teamPool.TeamName)
+}
+
+func (rps *reviewerPoolService) DetachPool(ctx context.Context, teamName, poolName string) ([]models.TeamPool, error) {
+	if poolName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	teamPools, err := rps.GetTeamPools(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	attached := slices.ContainsFunc(teamPools, func(teamPool models.TeamPool) bool {
+		return teamPool.PoolName == poolName
+	})
+	if !attached {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	err = rps.poolRepo.RemoveTeamPool(ctx, teamName, poolName)
+	if err != nil {
+		return nil, err
+	}
+	return rps.GetTeamPools(ctx, teamName)
+}
+
+func (rps *reviewerPoolService) GetTeamPools(ctx context.Context, teamName string) ([]models.TeamPool, error) {
+	if teamName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if err := rps.ensureTeamExists(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	teamPools, err := rps.poolRepo.GetTeamPools(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if teamPools == nil {
+		teamPools = []models.TeamPool{}
+	}
+	return teamPools, nil
+}
+
+func (rps *reviewerPoolService) ensureTeamExists(ctx context.Context, teamName string) error {
+	exists, err := rps.teamRepo.IsTeamExists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !*exists {
+		return errors.New(NOT_FOUND)
+	}
+	return nil
+}
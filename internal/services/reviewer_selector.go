@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+)
+
+// ReviewerSelector выбирает до count ревьюеров среди кандидатов, подходящих под фильтр
+type ReviewerSelector interface {
+	Select(ctx context.Context, filter models.CandidateFilter, count int) ([]string, error)
+}
+
+func defaultSelectors(userRepo repos.UserRepo, teamRepo repos.TeamRepo) map[models.ReviewerStrategy]ReviewerSelector {
+	return map[models.ReviewerStrategy]ReviewerSelector{
+		models.StrategyRandom:      NewRandomSelector(userRepo),
+		models.StrategyRoundRobin:  NewRoundRobinSelector(userRepo, teamRepo),
+		models.StrategyLeastLoaded: NewLeastLoadedSelector(userRepo),
+	}
+}
+
+func candidateIDs(candidates []models.ReviewCandidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.UserID)
+	}
+	return ids
+}
+
+type randomSelector struct {
+	userRepo repos.UserRepo
+}
+
+func NewRandomSelector(userRepo repos.UserRepo) ReviewerSelector {
+	return &randomSelector{
+		userRepo: userRepo,
+	}
+}
+
+func (rs *randomSelector) Select(ctx context.Context, filter models.CandidateFilter, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	candidates, err := rs.userRepo.GetReviewCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := candidateIDs(candidates)
+	rand.Shuffle(len(selected), func(i, j int) {
+		selected[i], selected[j] = selected[j], selected[i]
+	})
+	if len(selected) > count {
+		selected = selected[:count]
+	}
+	return selected, nil
+}
+
+// roundRobinSelector идет по кандидатам в порядке id, начиная после последнего назначенного в команде
+type roundRobinSelector struct {
+	userRepo repos.UserRepo
+	teamRepo repos.TeamRepo
+}
+
+func NewRoundRobinSelector(userRepo repos.UserRepo, teamRepo repos.TeamRepo) ReviewerSelector {
+	return &roundRobinSelector{
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+	}
+}
+
+func (rrs *roundRobinSelector) Select(ctx context.Context, filter models.CandidateFilter, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	candidates, err := rrs.userRepo.GetReviewCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sorted := candidateIDs(candidates)
+	sort.Strings(sorted)
+
+	last, err := rrs.teamRepo.GetLastRotatedReviewer(ctx, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	start := sort.SearchStrings(sorted, last)
+	if start < len(sorted) && sorted[start] == last {
+		start++
+	}
+
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+	selected := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		selected = append(selected, sorted[(start+i)%len(sorted)])
+	}
+
+	err = rrs.teamRepo.SetLastRotatedReviewer(ctx, filter.TeamName, selected[len(selected)-1])
+	if err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// leastLoadedSelector берет кандидатов с наименьшим числом OPEN ревью, ранжирование делает бд
+type leastLoadedSelector struct {
+	userRepo repos.UserRepo
+}
+
+func NewLeastLoadedSelector(userRepo repos.UserRepo) ReviewerSelector {
+	return &leastLoadedSelector{
+		userRepo: userRepo,
+	}
+}
+
+func (lls *leastLoadedSelector) Select(ctx context.Context, filter models.CandidateFilter, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	filter.Limit = count
+	candidates, err := lls.userRepo.GetReviewCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return candidateIDs(candidates), nil
+}
+
+// reassignmentPlan копит решения пакетного переназначения, которое сохраняется позже одной транзакцией:
+// сколько ревью уже роздано кандидатам и на ком остановилась ротация команд
+type reassignmentPlan struct {
+	load     map[string]int
+	rotation map[string]string
+}
+
+func newReassignmentPlan() *reassignmentPlan {
+	return &reassignmentPlan{
+		load:     make(map[string]int),
+		rotation: make(map[string]string),
+	}
+}
+
+func planSelectors(userRepo repos.UserRepo, teamRepo repos.TeamRepo, plan *reassignmentPlan) map[models.ReviewerStrategy]ReviewerSelector {
+	selectors := make(map[models.ReviewerStrategy]ReviewerSelector)
+	for _, strategy := range []models.ReviewerStrategy{models.StrategyRandom, models.StrategyRoundRobin, models.StrategyLeastLoaded} {
+		selectors[strategy] = &planSelector{
+			userRepo: userRepo,
+			teamRepo: teamRepo,
+			strategy: strategy,
+			plan:     plan,
+		}
+	}
+	return selectors
+}
+
+// planSelector выбирает ревьюеров стратегией команды для плана переназначений. К нагрузке из бд
+// добавляются ревью, уже розданные в плане, и кандидаты, достигшие с ними лимита, пропускаются.
+// Позиция ротации запоминается в плане, в бд ее сохраняют вместе с переназначениями
+type planSelector struct {
+	userRepo repos.UserRepo
+	teamRepo repos.TeamRepo
+	strategy models.ReviewerStrategy
+	plan     *reassignmentPlan
+}
+
+func (ps *planSelector) Select(ctx context.Context, filter models.CandidateFilter, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	filter.Limit = 0
+	candidates, err := ps.userRepo.GetReviewCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	free := make([]models.ReviewCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidate.OpenReviews += ps.plan.load[candidate.UserID]
+		if candidate.MaxOpenReviews > 0 && candidate.OpenReviews >= candidate.MaxOpenReviews {
+			continue
+		}
+		free = append(free, candidate)
+	}
+	if len(free) == 0 {
+		return nil, nil
+	}
+	if count > len(free) {
+		count = len(free)
+	}
+
+	switch ps.strategy {
+	case models.StrategyRoundRobin:
+		return ps.rotate(ctx, filter.TeamName, candidateIDs(free), count)
+	case models.StrategyLeastLoaded:
+		// бд уже упорядочила кандидатов по нагрузке со случайным порядком при равенстве
+		sort.SliceStable(free, func(i, j int) bool {
+			return free[i].OpenReviews < free[j].OpenReviews
+		})
+		return candidateIDs(free[:count]), nil
+	default:
+		selected := candidateIDs(free)
+		rand.Shuffle(len(selected), func(i, j int) {
+			selected[i], selected[j] = selected[j], selected[i]
+		})
+		return selected[:count], nil
+	}
+}
+
+// rotate идет по кандидатам в порядке id, как roundRobinSelector, начиная с позиции ротации,
+// запомненной в плане, а если команда в плане еще не встречалась - с сохраненной в бд
+func (ps *planSelector) rotate(ctx context.Context, teamName string, ids []string, count int) ([]string, error) {
+	sort.Strings(ids)
+
+	last, ok := ps.plan.rotation[teamName]
+	if !ok {
+		var err error
+		last, err = ps.teamRepo.GetLastRotatedReviewer(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start := sort.SearchStrings(ids, last)
+	if start < len(ids) && ids[start] == last {
+		start++
+	}
+
+	selected := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		selected = append(selected, ids[(start+i)%len(ids)])
+	}
+	ps.plan.rotation[teamName] = selected[len(selected)-1]
+	return selected, nil
+}
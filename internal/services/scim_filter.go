@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+type scimCondition struct {
+	attr  string
+	op    string
+	value string
+}
+
+// SCIMFilter - разобранный фильтр SCIM (RFC 7644, 3.4.2.2). Поддерживаются операторы
+// eq, ne, co, sw, ew и pr, объединенные через and. Имена атрибутов и строки сравниваются
+// без учета регистра. Пустой фильтр подходит под любой ресурс
+type SCIMFilter struct {
+	conditions []scimCondition
+}
+
+type scimToken struct {
+	text   string
+	quoted bool
+}
+
+func ParseSCIMFilter(filter string) (*SCIMFilter, error) {
+	tokens, err := scimTokens(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &SCIMFilter{}
+	for i := 0; i < len(tokens); {
+		if len(f.conditions) > 0 {
+			if tokens[i].quoted || !strings.EqualFold(tokens[i].text, "and") {
+				return nil, errors.New(INVALID_FILTER)
+			}
+			i++
+		}
+		if i+1 >= len(tokens) || tokens[i].quoted || tokens[i+1].quoted {
+			return nil, errors.New(INVALID_FILTER)
+		}
+
+		attr := strings.ToLower(tokens[i].text)
+		if strings.ContainsAny(attr, "[]()") {
+			return nil, errors.New(INVALID_FILTER)
+		}
+		op := strings.ToLower(tokens[i+1].text)
+		if op == "pr" {
+			f.conditions = append(f.conditions, scimCondition{attr: attr, op: op})
+			i += 2
+			continue
+		}
+
+		switch op {
+		case "eq", "ne", "co", "sw", "ew":
+		default:
+			return nil, errors.New(INVALID_FILTER)
+		}
+		if i+2 >= len(tokens) {
+			return nil, errors.New(INVALID_FILTER)
+		}
+		value := tokens[i+2].text
+		if !tokens[i+2].quoted {
+			// true, false, null и числа передаются без кавычек
+			value = strings.ToLower(value)
+		}
+		f.conditions = append(f.conditions, scimCondition{attr: attr, op: op, value: value})
+		i += 3
+	}
+	return f, nil
+}
+
+// Conditions возвращает условия фильтра для выборки в бд
+func (f *SCIMFilter) Conditions() []models.SCIMCondition {
+	conditions := make([]models.SCIMCondition, 0, len(f.conditions))
+	for _, cond := range f.conditions {
+		conditions = append(conditions, models.SCIMCondition{Attr: cond.attr, Op: cond.op, Value: cond.value})
+	}
+	return conditions
+}
+
+// Match проверяет ресурс, заданный значениями атрибутов. Ключи - имена атрибутов в нижнем
+// регистре, у многозначных атрибутов условие выполняется, если подходит любое значение
+func (f *SCIMFilter) Match(attrs map[string][]string) bool {
+	for _, cond := range f.conditions {
+		if !cond.match(attrs[cond.attr]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond scimCondition) match(values []string) bool {
+	switch cond.op {
+	case "pr":
+		for _, value := range values {
+			if value != "" {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		for _, value := range values {
+			if strings.EqualFold(value, cond.value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	want := strings.ToLower(cond.value)
+	for _, value := range values {
+		value = strings.ToLower(value)
+		switch {
+		case cond.op == "eq" && value == want,
+			cond.op == "co" && strings.Contains(value, want),
+			cond.op == "sw" && strings.HasPrefix(value, want),
+			cond.op == "ew" && strings.HasSuffix(value, want):
+			return true
+		}
+	}
+	return false
+}
+
+// scimTokens делит фильтр на слова по пробелам, строки в кавычках остаются одним словом
+func scimTokens(filter string) ([]scimToken, error) {
+	var tokens []scimToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, errors.New(INVALID_FILTER)
+			}
+			tokens = append(tokens, scimToken{text: sb.String(), quoted: true})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, scimToken{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
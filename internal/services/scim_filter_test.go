@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCIMFilter(t *testing.T) {
+	user := map[string][]string{
+		"id":       {"u1"},
+		"username": {"Alice.Smith"},
+		"active":   {"true"},
+		"groups":   {"backend"},
+	}
+
+	tests := []struct {
+		name   string
+		filter string
+		match  bool
+	}{
+		{"пустой фильтр", "", true},
+		{"eq без учета регистра", `userName eq "alice.smith"`, true},
+		{"eq не совпал", `userName eq "bob"`, false},
+		{"ne", `userName ne "bob"`, true},
+		{"co", `userName co "ce.sm"`, true},
+		{"sw", `userName sw "alice"`, true},
+		{"ew", `userName ew "smith"`, true},
+		{"pr для заданного атрибута", `groups pr`, true},
+		{"pr для отсутствующего атрибута", `externalId pr`, false},
+		{"булево значение без кавычек", `active eq True`, true},
+		{"and", `userName sw "alice" and active eq false`, false},
+		{"экранированная кавычка", `userName eq "alice\"smith"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := services.ParseSCIMFilter(tt.filter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.match, f.Match(user))
+		})
+	}
+
+	t.Run("многозначный атрибут", func(t *testing.T) {
+		f, err := services.ParseSCIMFilter(`members.value eq "u2"`)
+
+		assert.NoError(t, err)
+		assert.True(t, f.Match(map[string][]string{"members.value": {"u1", "u2"}}))
+	})
+
+	for _, filter := range []string{
+		`userName eq`,
+		`userName gt "a"`,
+		`userName eq "a" or id eq "b"`,
+		`userName eq "alice`,
+		`emails[type eq "work"] pr`,
+		`(userName eq "a")`,
+	} {
+		t.Run("неподдерживаемый фильтр "+filter, func(t *testing.T) {
+			_, err := services.ParseSCIMFilter(filter)
+
+			assert.EqualError(t, err, services.INVALID_FILTER)
+		})
+	}
+}
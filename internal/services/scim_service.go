@@ -0,0 +1,596 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/repos"
+)
+
+// SCIMService отображает ресурсы SCIM 2.0 на пользователей и команды. Изменения активности
+// и состава команд идут через UserService и TeamService, чтобы ревью ушедших переназначались
+// так же, как при вызовах основного API
+type SCIMService interface {
+	ListUsers(ctx context.Context, filter string, startIndex, count int) (*models.SCIMListResponse, error)
+	GetUser(ctx context.Context, id string) (*models.SCIMUser, error)
+	CreateUser(ctx context.Context, user *models.SCIMUser) (*models.SCIMUser, error)
+	ReplaceUser(ctx context.Context, id string, user *models.SCIMUser) (*models.SCIMUser, error)
+	PatchUser(ctx context.Context, id string, req *models.SCIMPatchRequest) (*models.SCIMUser, error)
+	DeleteUser(ctx context.Context, id string) error
+	ListGroups(ctx context.Context, filter string, startIndex, count int) (*models.SCIMListResponse, error)
+	GetGroup(ctx context.Context, id string) (*models.SCIMGroup, error)
+	CreateGroup(ctx context.Context, group *models.SCIMGroup) (*models.SCIMGroup, error)
+	ReplaceGroup(ctx context.Context, id string, group *models.SCIMGroup) (*models.SCIMGroup, error)
+	PatchGroup(ctx context.Context, id string, req *models.SCIMPatchRequest) (*models.SCIMGroup, error)
+	DeleteGroup(ctx context.Context, id string) error
+}
+
+type scimService struct {
+	userRepo    repos.UserRepo
+	teamRepo    repos.TeamRepo
+	userService UserService
+	teamService TeamService
+}
+
+func NewSCIMService(userRepo repos.UserRepo, teamRepo repos.TeamRepo, userService UserService, teamService TeamService) SCIMService {
+	return &scimService{
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		userService: userService,
+		teamService: teamService,
+	}
+}
+
+// toSCIMUser отображает пользователя, groups - все его команды, основная и дополнительные
+func toSCIMUser(user *models.User, teams []string) *models.SCIMUser {
+	active := user.IsActive
+	scimUser := &models.SCIMUser{
+		Schemas:  []string{models.SCIMUserSchema},
+		ID:       user.ID,
+		UserName: user.Username,
+		Active:   &active,
+		Meta: &models.SCIMMeta{
+			ResourceType: "User",
+			Location:     "/scim/v2/Users/" + user.ID,
+		},
+	}
+	for _, team := range teams {
+		scimUser.Groups = append(scimUser.Groups, models.SCIMRef{Value: team, Display: team})
+	}
+	return scimUser
+}
+
+func toSCIMGroup(team *models.Team) *models.SCIMGroup {
+	group := &models.SCIMGroup{
+		Schemas:     []string{models.SCIMGroupSchema},
+		ID:          team.Name,
+		DisplayName: team.Name,
+		Members:     make([]models.SCIMRef, 0, len(team.Members)),
+		Meta: &models.SCIMMeta{
+			ResourceType: "Group",
+			Location:     "/scim/v2/Groups/" + team.Name,
+		},
+	}
+	for _, member := range team.Members {
+		group.Members = append(group.Members, models.SCIMRef{Value: member.UserID, Display: member.Username})
+	}
+	return group
+}
+
+// scimQuery переводит фильтр и страницу по startIndex (с единицы) и count в выборку для бд
+func scimQuery(f *SCIMFilter, startIndex, count int) models.SCIMQuery {
+	return models.SCIMQuery{
+		Conditions: f.Conditions(),
+		Offset:     max(startIndex, 1) - 1,
+		Limit:      max(count, 0),
+	}
+}
+
+func scimList[T any](page []T, total, startIndex int) *models.SCIMListResponse {
+	return &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMListSchema},
+		TotalResults: total,
+		StartIndex:   max(startIndex, 1),
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func (ss *scimService) ListUsers(ctx context.Context, filter string, startIndex, count int) (*models.SCIMListResponse, error) {
+	f, err := ParseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	users, total, err := ss.userRepo.ListSCIMUsers(ctx, scimQuery(f, startIndex, count))
+	if err != nil {
+		return nil, err
+	}
+
+	page := make([]*models.SCIMUser, 0, len(users))
+	for i := range users {
+		page = append(page, toSCIMUser(&users[i].User, users[i].Teams))
+	}
+	return scimList(page, total, startIndex), nil
+}
+
+// scimUser отображает пользователя вместе со всеми его командами
+func (ss *scimService) scimUser(ctx context.Context, user *models.User) (*models.SCIMUser, error) {
+	teams, err := ss.userRepo.GetUserTeams(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMUser(user, teams), nil
+}
+
+func (ss *scimService) GetUser(ctx context.Context, id string) (*models.SCIMUser, error) {
+	user, err := ss.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return ss.scimUser(ctx, user)
+}
+
+func (ss *scimService) CreateUser(ctx context.Context, scimUser *models.SCIMUser) (*models.SCIMUser, error) {
+	if scimUser.UserName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	id := scimUser.ExternalID
+	if id == "" {
+		id = scimUser.UserName
+	}
+	if _, err := ss.userRepo.GetUser(ctx, id); err == nil {
+		return nil, errors.New(USER_EXISTS)
+	}
+
+	// пользователь из SCIM создается без команды, в команду его добавляет группа
+	user := &models.User{
+		ID:       id,
+		Username: scimUser.UserName,
+		IsActive: scimUser.Active == nil || *scimUser.Active,
+	}
+	if err := ss.userRepo.UpsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return toSCIMUser(user, nil), nil
+}
+
+// ReplaceUser заменяет userName и active. Отсутствующий active по умолчанию означает true
+func (ss *scimService) ReplaceUser(ctx context.Context, id string, scimUser *models.SCIMUser) (*models.SCIMUser, error) {
+	if scimUser.UserName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	return ss.updateUser(ctx, id, &scimUser.UserName, scimUser.Active == nil || *scimUser.Active)
+}
+
+// PatchUser поддерживает add и replace для userName и active, в том числе без path.
+// Остальные атрибуты, которые шлют провайдеры (name, emails), игнорируются
+func (ss *scimService) PatchUser(ctx context.Context, id string, req *models.SCIMPatchRequest) (*models.SCIMUser, error) {
+	user, err := ss.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	var username *string
+	active := user.IsActive
+	set := func(attr string, value json.RawMessage) error {
+		switch strings.ToLower(attr) {
+		case "username":
+			var name string
+			if err := json.Unmarshal(value, &name); err != nil || name == "" {
+				return errors.New(INVALID_INPUT)
+			}
+			username = &name
+		case "active":
+			parsed, err := scimBool(value)
+			if err != nil {
+				return err
+			}
+			active = parsed
+		}
+		return nil
+	}
+
+	for _, op := range req.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				if err := set(op.Path, op.Value); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return nil, errors.New(INVALID_INPUT)
+			}
+			for attr, value := range values {
+				if err := set(attr, value); err != nil {
+					return nil, err
+				}
+			}
+		case "remove":
+			switch strings.ToLower(op.Path) {
+			case "username", "active":
+				return nil, errors.New(INVALID_INPUT)
+			}
+		default:
+			return nil, errors.New(INVALID_INPUT)
+		}
+	}
+	return ss.updateUser(ctx, id, username, active)
+}
+
+func (ss *scimService) updateUser(ctx context.Context, id string, username *string, active bool) (*models.SCIMUser, error) {
+	user, err := ss.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	if username != nil && *username != user.Username {
+		user.Username = *username
+		if err := ss.userRepo.UpsertUser(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	if active != user.IsActive {
+		// ревью деактивированного переназначаются по настройке его команды
+		user, _, err = ss.userService.SetUserActive(ctx, id, active, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ss.scimUser(ctx, user)
+}
+
+// DeleteUser исключает пользователя из команды и деактивирует его. Запись остается в бд:
+// на нее ссылаются пулл реквесты и история ревью
+func (ss *scimService) DeleteUser(ctx context.Context, id string) error {
+	user, err := ss.userRepo.GetUser(ctx, id)
+	if err != nil {
+		return errors.New(NOT_FOUND)
+	}
+
+	if user.TeamName != "" {
+		_, err := ss.teamService.RemoveMembers(ctx, &models.TeamUsersRequest{
+			TeamName: user.TeamName,
+			UserIDs:  []string{user.ID},
+		})
+		return err
+	}
+	if user.IsActive {
+		_, _, err := ss.userService.SetUserActive(ctx, user.ID, false, nil)
+		return err
+	}
+	return nil
+}
+
+func (ss *scimService) ListGroups(ctx context.Context, filter string, startIndex, count int) (*models.SCIMListResponse, error) {
+	f, err := ParseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	teams, total, err := ss.teamRepo.ListSCIMGroups(ctx, scimQuery(f, startIndex, count))
+	if err != nil {
+		return nil, err
+	}
+
+	page := make([]*models.SCIMGroup, 0, len(teams))
+	for i := range teams {
+		page = append(page, toSCIMGroup(&teams[i]))
+	}
+	return scimList(page, total, startIndex), nil
+}
+
+func (ss *scimService) GetGroup(ctx context.Context, id string) (*models.SCIMGroup, error) {
+	team, err := ss.getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMGroup(team), nil
+}
+
+func (ss *scimService) getTeam(ctx context.Context, name string) (*models.Team, error) {
+	exists, err := ss.teamRepo.IsTeamExists(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !*exists {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return ss.teamRepo.GetTeam(ctx, name)
+}
+
+func (ss *scimService) CreateGroup(ctx context.Context, group *models.SCIMGroup) (*models.SCIMGroup, error) {
+	if group.DisplayName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	exists, err := ss.teamRepo.IsTeamExists(ctx, group.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	if *exists {
+		return nil, errors.New(TEAM_EXISTS)
+	}
+	memberIDs, err := ss.memberIDs(ctx, group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ss.teamRepo.CreateTeam(ctx, group.DisplayName); err != nil {
+		return nil, err
+	}
+	if err := ss.setMembers(ctx, group.DisplayName, memberIDs); err != nil {
+		return nil, err
+	}
+	return ss.GetGroup(ctx, group.DisplayName)
+}
+
+// ReplaceGroup переименовывает команду, если изменился displayName, и приводит состав к members
+func (ss *scimService) ReplaceGroup(ctx context.Context, id string, group *models.SCIMGroup) (*models.SCIMGroup, error) {
+	if group.DisplayName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if _, err := ss.getTeam(ctx, id); err != nil {
+		return nil, err
+	}
+	memberIDs, err := ss.memberIDs(ctx, group.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := ss.renameGroup(ctx, id, group.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	if err := ss.setMembers(ctx, name, memberIDs); err != nil {
+		return nil, err
+	}
+	return ss.GetGroup(ctx, name)
+}
+
+// PatchGroup поддерживает add, remove и replace для members, в том числе удаление
+// по пути members[value eq "id"], и replace для displayName
+func (ss *scimService) PatchGroup(ctx context.Context, id string, req *models.SCIMPatchRequest) (*models.SCIMGroup, error) {
+	if _, err := ss.getTeam(ctx, id); err != nil {
+		return nil, err
+	}
+
+	name := id
+	for _, op := range req.Operations {
+		attr, filter := strings.ToLower(op.Path), ""
+		if i := strings.Index(attr, "["); i >= 0 && strings.HasSuffix(attr, "]") {
+			attr, filter = attr[:i], op.Path[i+1:len(op.Path)-1]
+		}
+
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			values := map[string]json.RawMessage{attr: op.Value}
+			if attr == "" {
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					return nil, errors.New(INVALID_INPUT)
+				}
+			}
+			name, err = ss.patchGroupValues(ctx, name, strings.ToLower(op.Op) == "replace", values)
+		case "remove":
+			if attr == "members" {
+				err = ss.removeGroupMembers(ctx, name, filter, op.Value)
+			}
+		default:
+			err = errors.New(INVALID_INPUT)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ss.GetGroup(ctx, name)
+}
+
+func (ss *scimService) patchGroupValues(ctx context.Context, name string, replace bool, values map[string]json.RawMessage) (string, error) {
+	for attr, value := range values {
+		switch strings.ToLower(attr) {
+		case "displayname":
+			var displayName string
+			if err := json.Unmarshal(value, &displayName); err != nil || displayName == "" {
+				return "", errors.New(INVALID_INPUT)
+			}
+			renamed, err := ss.renameGroup(ctx, name, displayName)
+			if err != nil {
+				return "", err
+			}
+			name = renamed
+		case "members":
+			var refs []models.SCIMRef
+			if err := json.Unmarshal(value, &refs); err != nil {
+				return "", errors.New(INVALID_INPUT)
+			}
+			memberIDs, err := ss.memberIDs(ctx, refs)
+			if err != nil {
+				return "", err
+			}
+			if replace {
+				err = ss.setMembers(ctx, name, memberIDs)
+			} else {
+				err = ss.addMembers(ctx, name, memberIDs)
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return name, nil
+}
+
+// removeGroupMembers исключает участников, выбранных фильтром пути или перечисленных в value.
+// Без фильтра и value исключаются все участники
+func (ss *scimService) removeGroupMembers(ctx context.Context, name, filter string, value json.RawMessage) error {
+	team, err := ss.teamRepo.GetTeam(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	var selected []string
+	switch {
+	case filter != "":
+		f, err := ParseSCIMFilter(filter)
+		if err != nil {
+			return err
+		}
+		for _, member := range team.Members {
+			if f.Match(map[string][]string{"value": {member.UserID}, "display": {member.Username}}) {
+				selected = append(selected, member.UserID)
+			}
+		}
+	case len(value) > 0:
+		var refs []models.SCIMRef
+		if err := json.Unmarshal(value, &refs); err != nil {
+			return errors.New(INVALID_INPUT)
+		}
+		for _, ref := range refs {
+			selected = append(selected, ref.Value)
+		}
+	default:
+		for _, member := range team.Members {
+			selected = append(selected, member.UserID)
+		}
+	}
+	return ss.removeMembers(ctx, team, selected)
+}
+
+func (ss *scimService) renameGroup(ctx context.Context, name, displayName string) (string, error) {
+	if displayName == name {
+		return name, nil
+	}
+	_, err := ss.teamService.RenameTeam(ctx, &models.RenameTeamRequest{
+		TeamName:    name,
+		NewTeamName: displayName,
+	})
+	if err != nil {
+		return "", err
+	}
+	return displayName, nil
+}
+
+// memberIDs проверяет, что все участники группы существуют
+func (ss *scimService) memberIDs(ctx context.Context, refs []models.SCIMRef) ([]string, error) {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.Value)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	users, err := ss.userRepo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(users))
+	for _, user := range users {
+		found[user.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, errors.New(INVALID_INPUT)
+		}
+	}
+	return ids, nil
+}
+
+// setMembers приводит состав команды к memberIDs
+func (ss *scimService) setMembers(ctx context.Context, name string, memberIDs []string) error {
+	team, err := ss.teamRepo.GetTeam(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		keep[id] = true
+	}
+	var leaving []string
+	for _, member := range team.Members {
+		if !keep[member.UserID] {
+			leaving = append(leaving, member.UserID)
+		}
+	}
+	if err := ss.removeMembers(ctx, team, leaving); err != nil {
+		return err
+	}
+	return ss.addMembers(ctx, name, memberIDs)
+}
+
+// addMembers добавляет пользователей в команду. Пользователю без команды она становится
+// основной, остальным - дополнительной
+func (ss *scimService) addMembers(ctx context.Context, name string, memberIDs []string) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	users, err := ss.userRepo.GetUsersByIDs(ctx, memberIDs)
+	if err != nil {
+		return err
+	}
+
+	members := make([]models.TeamMember, 0, len(users))
+	for _, user := range users {
+		members = append(members, models.TeamMember{
+			UserID:   user.ID,
+			Username: user.Username,
+			IsActive: user.IsActive,
+			Skills:   user.Skills,
+		})
+	}
+	_, err = ss.teamService.AddMembers(ctx, &models.TeamMembersRequest{
+		TeamName: name,
+		Members:  members,
+	})
+	return err
+}
+
+func (ss *scimService) removeMembers(ctx context.Context, team *models.Team, userIDs []string) error {
+	members := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		members[member.UserID] = true
+	}
+	var leaving []string
+	for _, id := range userIDs {
+		if members[id] {
+			leaving = append(leaving, id)
+		}
+	}
+	if len(leaving) == 0 {
+		return nil
+	}
+
+	_, err := ss.teamService.RemoveMembers(ctx, &models.TeamUsersRequest{
+		TeamName: team.Name,
+		UserIDs:  leaving,
+	})
+	return err
+}
+
+func (ss *scimService) DeleteGroup(ctx context.Context, id string) error {
+	_, err := ss.teamService.DeleteTeam(ctx, id)
+	return err
+}
+
+// scimBool принимает true/false как булево значение или строку: Azure AD присылает "False"
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, errors.New(INVALID_INPUT)
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New(INVALID_INPUT)
+	}
+	return b, nil
+}
@@ -1,11 +1,31 @@
 package services
 
 const (
-	NOT_FOUND     = "NOT_FOUND"
-	PR_EXISTS     = "PR_EXISTS"
-	INVALID_INPUT = "INVALID_INPUT"
-	PR_MERGED     = "PR_MERGED"
-	NOT_ASSIGNED  = "NOT_ASSIGNED"
-	NO_CANDIDATE  = "NO_CANDIDATE"
-	TEAM_EXISTS   = "TEAM_EXISTS"
+	NOT_FOUND         = "NOT_FOUND"
+	PR_EXISTS         = "PR_EXISTS"
+	INVALID_INPUT     = "INVALID_INPUT"
+	PR_MERGED         = "PR_MERGED"
+	NOT_ASSIGNED      = "NOT_ASSIGNED"
+	NO_CANDIDATE      = "NO_CANDIDATE"
+	CAPACITY_EXCEEDED = "CAPACITY_EXCEEDED"
+	TEAM_EXISTS       = "TEAM_EXISTS"
+	NO_CODEOWNER      = "NO_CODEOWNER"
+
+	NOT_ENOUGH_APPROVALS = "NOT_ENOUGH_APPROVALS"
+	REVIEWER_IS_AUTHOR   = "REVIEWER_IS_AUTHOR"
+	USER_INACTIVE        = "USER_INACTIVE"
+	NOT_TEAM_MEMBER      = "NOT_TEAM_MEMBER"
+	ALREADY_ASSIGNED     = "ALREADY_ASSIGNED"
+	BELOW_MIN_REVIEWERS  = "BELOW_MIN_REVIEWERS"
+	PR_CLOSED            = "PR_CLOSED"
+	PR_DRAFT             = "PR_DRAFT"
+	INVALID_TRANSITION   = "INVALID_TRANSITION"
+	INVALID_CURSOR       = "INVALID_CURSOR"
+	TEAM_HAS_OPEN_PRS    = "TEAM_HAS_OPEN_PRS"
+	INVALID_FILTER       = "INVALID_FILTER"
+	USER_EXISTS          = "USER_EXISTS"
+	USER_HAS_OPEN_PRS    = "USER_HAS_OPEN_PRS"
+	HIERARCHY_CYCLE      = "HIERARCHY_CYCLE"
+	USER_UNAVAILABLE     = "USER_UNAVAILABLE"
+	REVIEWER_AT_CAPACITY = "REVIEWER_AT_CAPACITY"
 )
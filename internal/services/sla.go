@@ -0,0 +1,51 @@
+package services
+
+import (
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+// SLAExpired проверяет, истек ли у назначения SLA команды. При SLABusinessHoursOnly
+// время считается только в рабочие часы будних дней в часовом поясе команды
+func SLAExpired(assignedAt, now time.Time, settings *models.TeamSettings) bool {
+	if settings.ReviewSLAHours <= 0 {
+		return false
+	}
+
+	sla := time.Duration(settings.ReviewSLAHours) * time.Hour
+	if !settings.SLABusinessHoursOnly {
+		return now.Sub(assignedAt) >= sla
+	}
+	return BusinessDuration(assignedAt, now, settings.BusinessHoursStart, settings.BusinessHoursEnd, settings.Location()) >= sla
+}
+
+// BusinessDuration возвращает время между from и to, попавшее в интервал [startHour, endHour)
+// с понедельника по пятницу
+func BusinessDuration(from, to time.Time, startHour, endHour int, loc *time.Location) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	from, to = from.In(loc), to.In(loc)
+	var total time.Duration
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+
+		// time.Date сам нормализует endHour = 24 в полночь следующего дня
+		open := time.Date(day.Year(), day.Month(), day.Day(), startHour, 0, 0, 0, loc)
+		closed := time.Date(day.Year(), day.Month(), day.Day(), endHour, 0, 0, 0, loc)
+		if open.Before(from) {
+			open = from
+		}
+		if closed.After(to) {
+			closed = to
+		}
+		if closed.After(open) {
+			total += closed.Sub(open)
+		}
+	}
+	return total
+}
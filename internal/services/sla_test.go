@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+	"github.com/forzeyy/avito-autumn/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusinessDuration(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+
+	// 2025-07-04 - пятница
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		loc      *time.Location
+		expected time.Duration
+	}{
+		{"внутри рабочего дня", time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC), time.Date(2025, 7, 4, 13, 30, 0, 0, time.UTC), time.UTC, 3*time.Hour + 30*time.Minute},
+		{"назначение до начала рабочего дня", time.Date(2025, 7, 4, 6, 0, 0, 0, time.UTC), time.Date(2025, 7, 4, 11, 0, 0, 0, time.UTC), time.UTC, 2 * time.Hour},
+		{"выходные не считаются", time.Date(2025, 7, 4, 17, 0, 0, 0, time.UTC), time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC), time.UTC, 2 * time.Hour},
+		{"ночь не считается", time.Date(2025, 7, 1, 16, 0, 0, 0, time.UTC), time.Date(2025, 7, 2, 10, 0, 0, 0, time.UTC), time.UTC, 3 * time.Hour},
+		{"часовой пояс команды", time.Date(2025, 7, 4, 6, 0, 0, 0, time.UTC), time.Date(2025, 7, 4, 8, 0, 0, 0, time.UTC), msk, 2 * time.Hour},
+		{"конец раньше начала", time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC), time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC), time.UTC, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.BusinessDuration(tt.from, tt.to, 9, 18, tt.loc))
+		})
+	}
+}
+
+func TestSLAExpired(t *testing.T) {
+	settings := models.DefaultTeamSettings("backend")
+	settings.ReviewSLAHours = 4
+
+	// вечер пятницы - утро понедельника
+	assignedAt := time.Date(2025, 7, 4, 16, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+
+	t.Run("календарное время", func(t *testing.T) {
+		assert.True(t, services.SLAExpired(assignedAt, now, settings))
+	})
+
+	t.Run("только рабочие часы", func(t *testing.T) {
+		businessOnly := *settings
+		businessOnly.SLABusinessHoursOnly = true
+		assert.False(t, services.SLAExpired(assignedAt, now, &businessOnly))
+		assert.True(t, services.SLAExpired(assignedAt, now.Add(time.Hour), &businessOnly))
+	})
+
+	t.Run("SLA не задан", func(t *testing.T) {
+		noSLA := *settings
+		noSLA.ReviewSLAHours = 0
+		assert.False(t, services.SLAExpired(assignedAt, now, &noSLA))
+	})
+}
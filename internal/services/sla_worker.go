@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SLAWorker периодически переназначает ревьюеров с просроченным SLA
+type SLAWorker struct {
+	prService PRService
+	interval  time.Duration
+}
+
+func NewSLAWorker(prService PRService, interval time.Duration) *SLAWorker {
+	return &SLAWorker{
+		prService: prService,
+		interval:  interval,
+	}
+}
+
+// Run блокируется до отмены ctx
+func (w *SLAWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			reassigned, err := w.prService.ReassignStaleReviews(ctx, now)
+			if err != nil {
+				log.Printf("ошибка при переназначении просроченных ревью: %v", err)
+			}
+			for _, r := range reassigned {
+				log.Printf("SLA истек: пулл реквест %s передан от %s к %s", r.PRID, r.OldReviewerID, r.NewReviewerID)
+			}
+		}
+	}
+}
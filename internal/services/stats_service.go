@@ -34,8 +34,22 @@ func (ss *statsService) GetStats(ctx context.Context) (*models.StatsResponse, er
 		return nil, err
 	}
 
+	crossTeam, err := ss.prRepo.GetCrossTeamStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	crossTeamPRs := 0
+	for _, s := range crossTeam {
+		crossTeamPRs += s.PRCount
+	}
+	if crossTeam == nil {
+		crossTeam = []models.CrossTeamStats{}
+	}
+
 	return &models.StatsResponse{
 		TotalPRsCreated: totalPRs,
 		ReviewsByUser:   userStats,
+		CrossTeamPRs:    crossTeamPRs,
+		CrossTeam:       crossTeam,
 	}, nil
 }
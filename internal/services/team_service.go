@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
 	"github.com/forzeyy/avito-autumn/internal/repos"
@@ -11,17 +12,31 @@ import (
 type TeamService interface {
 	CreateTeam(ctx context.Context, team *models.Team) error
 	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	UpdateTeamSettings(ctx context.Context, update *models.TeamSettingsUpdate) (*models.TeamSettings, error)
+	DeactivateUsers(ctx context.Context, req *models.TeamUsersRequest) (*models.DeactivationResult, error)
+	AddMembers(ctx context.Context, req *models.TeamMembersRequest) (*models.Team, error)
+	RemoveMembers(ctx context.Context, req *models.TeamUsersRequest) (*models.DeactivationResult, error)
+	MoveUser(ctx context.Context, req *models.MoveUserRequest) (*models.User, *models.ReassignmentReport, error)
+	RenameTeam(ctx context.Context, req *models.RenameTeamRequest) (*models.Team, error)
+	SetParentTeam(ctx context.Context, req *models.SetParentTeamRequest) (*models.Team, error)
+	DeleteTeam(ctx context.Context, teamName string) (*models.TeamDeletionResult, error)
+	SyncTeam(ctx context.Context, req *models.TeamSyncRequest, dryRun bool) (*models.TeamSyncDiff, error)
 }
 
 type teamService struct {
-	teamRepo repos.TeamRepo
-	userRepo repos.UserRepo
+	teamRepo  repos.TeamRepo
+	userRepo  repos.UserRepo
+	prRepo    repos.PRRepo
+	prService PRService
 }
 
-func NewTeamService(teamRepo repos.TeamRepo, userRepo repos.UserRepo) TeamService {
+func NewTeamService(teamRepo repos.TeamRepo, userRepo repos.UserRepo, prRepo repos.PRRepo, prService PRService) TeamService {
 	return &teamService{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		prService: prService,
 	}
 }
 
@@ -33,11 +48,29 @@ func (ts *teamService) CreateTeam(ctx context.Context, team *models.Team) error
 	if *exists {
 		return errors.New(TEAM_EXISTS)
 	}
+	if team.ReviewerStrategy != "" && !team.ReviewerStrategy.IsValid() {
+		return errors.New(INVALID_INPUT)
+	}
+	if team.ParentTeam != "" {
+		exists, err := ts.teamRepo.IsTeamExists(ctx, team.ParentTeam)
+		if err != nil {
+			return err
+		}
+		if !*exists {
+			return errors.New(INVALID_INPUT)
+		}
+	}
 
 	err = ts.teamRepo.CreateTeam(ctx, team.Name)
 	if err != nil {
 		return err
 	}
+	if team.ParentTeam != "" {
+		err = ts.teamRepo.SetParentTeam(ctx, team.Name, team.ParentTeam)
+		if err != nil {
+			return err
+		}
+	}
 
 	for _, member := range team.Members {
 		user := &models.User{
@@ -45,13 +78,26 @@ func (ts *teamService) CreateTeam(ctx context.Context, team *models.Team) error
 			Username: member.Username,
 			TeamName: team.Name,
 			IsActive: member.IsActive,
+			Skills:   member.Skills,
 		}
 		err := ts.userRepo.UpsertUser(ctx, user)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+
+	if team.ReviewerStrategy != "" {
+		settings := models.DefaultTeamSettings(team.Name)
+		settings.ReviewerStrategy = team.ReviewerStrategy
+		err := ts.teamRepo.UpsertTeamSettings(ctx, settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	// у перешедших в команду авторов могли остаться пулл реквесты без ревьюеров
+	_, err = ts.prService.BackfillReviewers(ctx, []string{team.Name})
+	return err
 }
 
 func (ts *teamService) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
@@ -59,5 +105,431 @@ func (ts *teamService) GetTeam(ctx context.Context, teamName string) (*models.Te
 	if err != nil {
 		return nil, err
 	}
+
+	settings, err := ts.teamRepo.GetTeamSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	team.ReviewerStrategy = settings.ReviewerStrategy
+
+	ancestors, err := ts.teamRepo.GetTeamAncestors(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors) > 0 {
+		team.ParentTeam = ancestors[0]
+	}
 	return team, nil
 }
+
+// SetParentTeam переносит команду в иерархии. Родителем не может стать сама команда
+// или ее потомок, иначе эскалация ходила бы по кругу
+func (ts *teamService) SetParentTeam(ctx context.Context, req *models.SetParentTeamRequest) (*models.Team, error) {
+	if req.TeamName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	for _, name := range []string{req.TeamName, req.ParentTeam} {
+		if name == "" {
+			continue
+		}
+		exists, err := ts.teamRepo.IsTeamExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !*exists {
+			return nil, errors.New(NOT_FOUND)
+		}
+	}
+
+	if req.ParentTeam != "" {
+		if req.ParentTeam == req.TeamName {
+			return nil, errors.New(HIERARCHY_CYCLE)
+		}
+		ancestors, err := ts.teamRepo.GetTeamAncestors(ctx, req.ParentTeam)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(ancestors, req.TeamName) {
+			return nil, errors.New(HIERARCHY_CYCLE)
+		}
+	}
+
+	err := ts.teamRepo.SetParentTeam(ctx, req.TeamName, req.ParentTeam)
+	if err != nil {
+		return nil, err
+	}
+	return ts.GetTeam(ctx, req.TeamName)
+}
+
+func (ts *teamService) GetTeamSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	if teamName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	exists, err := ts.teamRepo.IsTeamExists(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if !*exists {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	return ts.teamRepo.GetTeamSettings(ctx, teamName)
+}
+
+func (ts *teamService) UpdateTeamSettings(ctx context.Context, update *models.TeamSettingsUpdate) (*models.TeamSettings, error) {
+	settings, err := ts.GetTeamSettings(ctx, update.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	update.Apply(settings)
+	if !settings.IsValid() {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	err = ts.teamRepo.UpsertTeamSettings(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// DeactivateUsers деактивирует участников команды и распределяет их OPEN ревью между оставшимися
+// активными участниками по нагрузке. Все изменения применяются одной транзакцией
+func (ts *teamService) DeactivateUsers(ctx context.Context, req *models.TeamUsersRequest) (*models.DeactivationResult, error) {
+	userIDs, _, err := ts.teamUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	report, err := ts.planReassignments(ctx, userIDs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := ts.userRepo.DeactivateUsers(ctx, req.TeamName, userIDs, report.Reassigned)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DeactivationResult{
+		TeamName:    req.TeamName,
+		Deactivated: users,
+		Reassigned:  report.Reassigned,
+		NoCandidate: report.NoCandidate,
+	}, nil
+}
+
+// RemoveMembers исключает пользователей из команды. Для кого она основная, те деактивируются
+// так же, как в DeactivateUsers, и остаются без команд. Дополнительные участники остаются
+// активными в основной команде, передаются только их ревью пулл реквестов этой команды.
+// Собственные пулл реквесты и история ревью сохраняются
+func (ts *teamService) RemoveMembers(ctx context.Context, req *models.TeamUsersRequest) (*models.DeactivationResult, error) {
+	userIDs, primary, err := ts.teamUsers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	report, err := ts.planReassignments(ctx, userIDs, func(assignment models.OpenAssignment) bool {
+		return primary[assignment.ReviewerID] || assignment.TeamName == req.TeamName
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := ts.userRepo.RemoveTeamMembers(ctx, req.TeamName, userIDs, report.Reassigned)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DeactivationResult{
+		TeamName:    req.TeamName,
+		Deactivated: []models.User{},
+		Reassigned:  report.Reassigned,
+		NoCandidate: report.NoCandidate,
+	}
+	for _, user := range users {
+		if primary[user.ID] {
+			result.Deactivated = append(result.Deactivated, user)
+		} else {
+			result.Detached = append(result.Detached, user)
+		}
+	}
+	return result, nil
+}
+
+// teamUsers проверяет, что пользователи состоят в команде, и возвращает их id без повторов
+// и тех из них, для кого команда основная
+func (ts *teamService) teamUsers(ctx context.Context, req *models.TeamUsersRequest) ([]string, map[string]bool, error) {
+	if req.TeamName == "" || len(req.UserIDs) == 0 {
+		return nil, nil, errors.New(INVALID_INPUT)
+	}
+
+	team, err := ts.teamRepo.GetTeam(ctx, req.TeamName)
+	if err != nil {
+		return nil, nil, err
+	}
+	members := make(map[string]bool, len(team.Members))
+	primary := make(map[string]bool, len(team.Members))
+	for _, member := range team.Members {
+		members[member.UserID] = true
+		primary[member.UserID] = member.IsPrimary
+	}
+
+	userIDs := make([]string, 0, len(req.UserIDs))
+	seen := make(map[string]bool, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if !members[id] {
+			return nil, nil, errors.New(NOT_FOUND)
+		}
+		if !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	return userIDs, primary, nil
+}
+
+// planReassignments распределяет OPEN ревью уходящих пользователей внутри команд пулл реквестов:
+// ревью достается участникам команды, которая ревьюит пулл реквест. keep отбирает передаваемые ревью,
+// nil - все. extra дополняет кандидатов команды теми, кто войдет в нее вместе с изменением
+func (ts *teamService) planReassignments(ctx context.Context, userIDs []string, keep func(models.OpenAssignment) bool,
+	extra map[string][]models.ReviewCandidate) (*models.ReassignmentReport, error) {
+	assignments, err := ts.prRepo.GetOpenAssignments(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []string
+	byTeam := make(map[string][]models.OpenAssignment)
+	for _, assignment := range assignments {
+		if keep != nil && !keep(assignment) {
+			continue
+		}
+		if _, ok := byTeam[assignment.TeamName]; !ok {
+			teams = append(teams, assignment.TeamName)
+		}
+		byTeam[assignment.TeamName] = append(byTeam[assignment.TeamName], assignment)
+	}
+
+	report := &models.ReassignmentReport{
+		Reassigned:  []models.Reassignment{},
+		NoCandidate: []string{},
+	}
+	// участник нескольких команд может получить ревью в каждой из них,
+	// поэтому уже розданные ревью добавляются к его нагрузке в следующих командах
+	received := make(map[string]int)
+	for _, team := range teams {
+		var candidates []models.ReviewCandidate
+		// у пулл реквеста автора без команды передавать ревью некому
+		if team != "" {
+			candidates, err = ts.userRepo.GetTeamWorkload(ctx, team, userIDs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		candidates = append(candidates, extra[team]...)
+		for i := range candidates {
+			candidates[i].OpenReviews += received[candidates[i].UserID]
+		}
+
+		part := BalanceReassignments(byTeam[team], candidates, models.ReasonReorg)
+		for _, move := range part.Reassigned {
+			received[move.NewReviewerID]++
+		}
+		report.Reassigned = append(report.Reassigned, part.Reassigned...)
+		report.NoCandidate = append(report.NoCandidate, part.NoCandidate...)
+	}
+	return report, nil
+}
+
+// teamOnly оставляет ревью пулл реквестов одной команды
+func teamOnly(teamName string) func(models.OpenAssignment) bool {
+	return func(assignment models.OpenAssignment) bool {
+		return assignment.TeamName == teamName
+	}
+}
+
+// AddMembers добавляет в существующую команду новых пользователей и уже заведенных участников.
+// Данные уже заведенного пользователя не меняются: пользователю без команды она становится
+// основной, для пользователя из другой команды - дополнительной. Сменить основную команду
+// можно через MoveUser. Пулл реквестам команды, которым не хватало ревьюеров, они добираются
+// из пополненного состава
+func (ts *teamService) AddMembers(ctx context.Context, req *models.TeamMembersRequest) (*models.Team, error) {
+	if req.TeamName == "" || len(req.Members) == 0 {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if err := ts.ensureTeamExists(ctx, req.TeamName); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*models.User)
+	for _, member := range req.Members {
+		if member.UserID == "" || member.Username == "" {
+			return nil, errors.New(INVALID_INPUT)
+		}
+		user, err := ts.userRepo.GetUser(ctx, member.UserID)
+		if err == nil {
+			existing[member.UserID] = user
+		}
+	}
+
+	for _, member := range req.Members {
+		user, ok := existing[member.UserID]
+		switch {
+		case !ok:
+			err := ts.userRepo.UpsertUser(ctx, &models.User{
+				ID:       member.UserID,
+				Username: member.Username,
+				TeamName: req.TeamName,
+				IsActive: member.IsActive,
+				Skills:   member.Skills,
+			})
+			if err != nil {
+				return nil, err
+			}
+		case user.TeamName == "":
+			// меняется только основная команда, активность и навыки пользователя остаются прежними
+			if _, err := ts.userRepo.MoveUser(ctx, member.UserID, "", req.TeamName, nil); err != nil {
+				return nil, err
+			}
+		default:
+			if err := ts.userRepo.AddTeamMembership(ctx, req.TeamName, member.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := ts.prService.BackfillReviewers(ctx, []string{req.TeamName}); err != nil {
+		return nil, err
+	}
+	return ts.GetTeam(ctx, req.TeamName)
+}
+
+// MoveUser меняет основную команду пользователя. Его OPEN ревью пулл реквестов прежней команды
+// распределяются между ее участниками, собственные пулл реквесты остаются с текущими ревьюерами
+func (ts *teamService) MoveUser(ctx context.Context, req *models.MoveUserRequest) (*models.User, *models.ReassignmentReport, error) {
+	if req.UserID == "" || req.TeamName == "" {
+		return nil, nil, errors.New(INVALID_INPUT)
+	}
+
+	user, err := ts.userRepo.GetUser(ctx, req.UserID)
+	if err != nil {
+		return nil, nil, errors.New(NOT_FOUND)
+	}
+	if err := ts.ensureTeamExists(ctx, req.TeamName); err != nil {
+		return nil, nil, err
+	}
+	report := &models.ReassignmentReport{
+		Reassigned:  []models.Reassignment{},
+		NoCandidate: []string{},
+	}
+	if user.TeamName == req.TeamName {
+		return user, report, nil
+	}
+
+	if user.TeamName != "" {
+		report, err = ts.planReassignments(ctx, []string{user.ID}, teamOnly(user.TeamName), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	moved, err := ts.userRepo.MoveUser(ctx, user.ID, user.TeamName, req.TeamName, report.Reassigned)
+	if err != nil {
+		return nil, nil, err
+	}
+	return moved, report, nil
+}
+
+func (ts *teamService) RenameTeam(ctx context.Context, req *models.RenameTeamRequest) (*models.Team, error) {
+	if req.TeamName == "" || req.NewTeamName == "" || req.TeamName == req.NewTeamName {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if err := ts.ensureTeamExists(ctx, req.TeamName); err != nil {
+		return nil, err
+	}
+
+	exists, err := ts.teamRepo.IsTeamExists(ctx, req.NewTeamName)
+	if err != nil {
+		return nil, err
+	}
+	if *exists {
+		return nil, errors.New(TEAM_EXISTS)
+	}
+
+	err = ts.teamRepo.RenameTeam(ctx, req.TeamName, req.NewTeamName)
+	if err != nil {
+		return nil, err
+	}
+	return ts.GetTeam(ctx, req.NewTeamName)
+}
+
+// DeleteTeam удаляет команду, если у ее участников нет DRAFT и OPEN пулл реквестов:
+// после удаления ревьюеров для них подбирать не из кого. Участники не деактивируются и теряют
+// только членство в ней. Их OPEN ревью, которые держались на участии в удаленной команде,
+// распределяются внутри команд пулл реквестов, не нашедшие замены возвращаются отдельным списком
+func (ts *teamService) DeleteTeam(ctx context.Context, teamName string) (*models.TeamDeletionResult, error) {
+	if teamName == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if err := ts.ensureTeamExists(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	open, err := ts.prRepo.ListPRs(ctx, models.PRListFilter{
+		TeamName: teamName,
+		Statuses: []models.Status{models.StatusDraft, models.StatusOpen},
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		return nil, errors.New(TEAM_HAS_OPEN_PRS)
+	}
+
+	team, err := ts.teamRepo.GetTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	// ревью остается за участником, если он и без удаленной команды состоит в команде пулл реквеста
+	memberIDs := make([]string, 0, len(team.Members))
+	remaining := make(map[string][]string, len(team.Members))
+	for _, member := range team.Members {
+		teams, err := ts.userRepo.GetUserTeams(ctx, member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		memberIDs = append(memberIDs, member.UserID)
+		remaining[member.UserID] = teams
+	}
+	report, err := ts.planReassignments(ctx, memberIDs, func(assignment models.OpenAssignment) bool {
+		return assignment.TeamName == teamName || !slices.Contains(remaining[assignment.ReviewerID], assignment.TeamName)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := ts.teamRepo.DeleteTeam(ctx, teamName, report.Reassigned)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TeamDeletionResult{
+		TeamName:    teamName,
+		Detached:    append([]models.User{}, users...),
+		Reassigned:  report.Reassigned,
+		NoCandidate: report.NoCandidate,
+	}, nil
+}
+
+func (ts *teamService) ensureTeamExists(ctx context.Context, teamName string) error {
+	exists, err := ts.teamRepo.IsTeamExists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !*exists {
+		return errors.New(NOT_FOUND)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/forzeyy/avito-autumn/internal/models"
+)
+
+// SyncTeam приводит состав команды к переданному: создает новых пользователей, обновляет имена
+// и навыки, переводит участников из других команд, активирует вернувшихся и деактивирует
+// выбывших. OPEN ревью выбывших распределяются внутри команды, ревью переведенных - внутри
+// их прежних команд. Если в команде появились новые или вернувшиеся участники, пулл реквестам
+// команды добираются недостающие ревьюеры. Повторный вызов с тем же составом ничего не меняет.
+// При dryRun возвращает diff, не применяя его
+func (ts *teamService) SyncTeam(ctx context.Context, req *models.TeamSyncRequest, dryRun bool) (*models.TeamSyncDiff, error) {
+	if req.TeamName == "" || len(req.Members) == 0 {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	rosterIDs := make([]string, 0, len(req.Members))
+	inRoster := make(map[string]bool, len(req.Members))
+	for _, member := range req.Members {
+		if member.UserID == "" || member.Username == "" || inRoster[member.UserID] {
+			return nil, errors.New(INVALID_INPUT)
+		}
+		inRoster[member.UserID] = true
+		rosterIDs = append(rosterIDs, member.UserID)
+	}
+
+	exists, err := ts.teamRepo.IsTeamExists(ctx, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	current := &models.Team{Name: req.TeamName}
+	if *exists {
+		current, err = ts.teamRepo.GetTeam(ctx, req.TeamName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	known, err := ts.userRepo.GetUsersByIDs(ctx, rosterIDs)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string]models.User, len(known))
+	for _, user := range known {
+		users[user.ID] = user
+	}
+
+	diff := &models.TeamSyncDiff{
+		TeamName:      req.TeamName,
+		DryRun:        dryRun,
+		TeamCreated:   !*exists,
+		Created:       []models.User{},
+		Renamed:       []models.UsernameChange{},
+		MovedIn:       []models.TeamMove{},
+		Reactivated:   []string{},
+		SkillsChanged: []string{},
+		Deactivated:   []string{},
+		Reassigned:    []models.Reassignment{},
+		NoCandidate:   []string{},
+	}
+
+	var upserts []models.User
+	// новички команды после синхронизации тоже могут принять ревью выбывших
+	var newcomers []models.User
+	for _, member := range req.Members {
+		target := models.User{
+			ID:       member.UserID,
+			Username: member.Username,
+			TeamName: req.TeamName,
+			IsActive: true,
+			Skills:   models.NormalizeTags(member.Skills),
+		}
+
+		user, found := users[member.UserID]
+		if !found {
+			diff.Created = append(diff.Created, target)
+			upserts = append(upserts, target)
+			newcomers = append(newcomers, target)
+			continue
+		}
+
+		changed := false
+		if user.TeamName != req.TeamName {
+			diff.MovedIn = append(diff.MovedIn, models.TeamMove{UserID: user.ID, FromTeam: user.TeamName})
+			changed = true
+		}
+		if user.Username != member.Username {
+			diff.Renamed = append(diff.Renamed, models.UsernameChange{
+				UserID:      user.ID,
+				OldUsername: user.Username,
+				NewUsername: member.Username,
+			})
+			changed = true
+		}
+		if !user.IsActive {
+			diff.Reactivated = append(diff.Reactivated, user.ID)
+			changed = true
+		}
+		if member.Skills == nil {
+			target.Skills = user.Skills
+		} else if !slices.Equal(target.Skills, user.Skills) {
+			diff.SkillsChanged = append(diff.SkillsChanged, user.ID)
+			changed = true
+		}
+
+		if changed {
+			upserts = append(upserts, target)
+		}
+		if user.TeamName != req.TeamName || !user.IsActive {
+			target.MaxOpenReviews = user.MaxOpenReviews
+			newcomers = append(newcomers, target)
+		}
+	}
+
+	// дополнительные участники не входят в состав, который задает синхронизация
+	for _, member := range current.Members {
+		if member.IsPrimary && member.IsActive && !inRoster[member.UserID] {
+			diff.Deactivated = append(diff.Deactivated, member.UserID)
+		}
+	}
+
+	if err := ts.planSyncReassignments(ctx, diff, newcomers); err != nil {
+		return nil, err
+	}
+
+	if dryRun || diff.IsEmpty() {
+		return diff, nil
+	}
+	err = ts.teamRepo.SyncTeam(ctx, req.TeamName, upserts, diff.Deactivated, diff.Reassigned)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diff.Created) > 0 || len(diff.MovedIn) > 0 || len(diff.Reactivated) > 0 {
+		if _, err := ts.prService.BackfillReviewers(ctx, []string{req.TeamName}); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// planSyncReassignments распределяет OPEN ревью выбывших внутри команд пулл реквестов, в самой
+// команде - и между ее новичками. Ревью переведенных остаются внутри их прежних команд
+func (ts *teamService) planSyncReassignments(ctx context.Context, diff *models.TeamSyncDiff, newcomers []models.User) error {
+	merge := func(report *models.ReassignmentReport) {
+		diff.Reassigned = append(diff.Reassigned, report.Reassigned...)
+		diff.NoCandidate = append(diff.NoCandidate, report.NoCandidate...)
+	}
+
+	if len(diff.Deactivated) > 0 {
+		settings, err := ts.teamRepo.GetTeamSettings(ctx, diff.TeamName)
+		if err != nil {
+			return err
+		}
+		var candidates []models.ReviewCandidate
+		for _, user := range newcomers {
+			maxOpenReviews := user.MaxOpenReviews
+			if maxOpenReviews == 0 {
+				maxOpenReviews = settings.MaxOpenReviews
+			}
+			candidates = append(candidates, models.ReviewCandidate{
+				UserID:         user.ID,
+				Username:       user.Username,
+				TeamName:       diff.TeamName,
+				MaxOpenReviews: maxOpenReviews,
+				Skills:         user.Skills,
+			})
+		}
+		report, err := ts.planReassignments(ctx, diff.Deactivated, nil, map[string][]models.ReviewCandidate{
+			diff.TeamName: candidates,
+		})
+		if err != nil {
+			return err
+		}
+		merge(report)
+	}
+
+	var fromTeams []string
+	leaving := make(map[string][]string)
+	for _, move := range diff.MovedIn {
+		if move.FromTeam == "" {
+			continue
+		}
+		if _, ok := leaving[move.FromTeam]; !ok {
+			fromTeams = append(fromTeams, move.FromTeam)
+		}
+		leaving[move.FromTeam] = append(leaving[move.FromTeam], move.UserID)
+	}
+	for _, team := range fromTeams {
+		report, err := ts.planReassignments(ctx, leaving[team], teamOnly(team), nil)
+		if err != nil {
+			return err
+		}
+		merge(report)
+	}
+	return nil
+}
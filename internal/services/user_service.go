@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 
 	"github.com/forzeyy/avito-autumn/internal/models"
@@ -9,34 +10,268 @@ import (
 )
 
 type UserService interface {
-	SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
-	GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	SetUserActive(ctx context.Context, userID string, isActive bool, reassign *bool) (*models.User, *models.ReassignmentReport, error)
+	GetPRsByReviewer(ctx context.Context, userID string, statuses []models.Status) ([]models.PullRequestShort, error)
+	SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error)
+	SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews int) (*models.User, error)
+	AddUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error)
+	GetUnavailability(ctx context.Context, userID string, includeExpired bool) ([]models.Unavailability, error)
+	UpdateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error)
+	DeleteUnavailability(ctx context.Context, id int64, userID string) error
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	ListUsers(ctx context.Context, filter models.UserListFilter, cursor string) (*models.UserPage, error)
+	DeleteUser(ctx context.Context, userID string) (*models.User, *models.ReassignmentReport, error)
 }
 
 type userService struct {
-	userRepo repos.UserRepo
-	prRepo   repos.PRRepo
+	userRepo           repos.UserRepo
+	prRepo             repos.PRRepo
+	unavailabilityRepo repos.UnavailabilityRepo
+	teamRepo           repos.TeamRepo
+	prService          PRService
 }
 
-func NewUserService(userRepo repos.UserRepo, prRepo repos.PRRepo) UserService {
+func NewUserService(userRepo repos.UserRepo, prRepo repos.PRRepo, unavailabilityRepo repos.UnavailabilityRepo, teamRepo repos.TeamRepo, prService PRService) UserService {
 	return &userService{
-		userRepo: userRepo,
-		prRepo:   prRepo,
+		userRepo:           userRepo,
+		prRepo:             prRepo,
+		unavailabilityRepo: unavailabilityRepo,
+		teamRepo:           teamRepo,
+		prService:          prService,
 	}
 }
 
-func (us *userService) SetUserActive(ctx context.Context, userID string, isActive bool) (*models.User, error) {
-	user, err := us.userRepo.SetUserActive(ctx, userID, isActive)
+// SetUserActive меняет активность пользователя. При деактивации его OPEN ревью передаются
+// другим участникам команды, если это запрошено явно или включено в настройках команды,
+// при активации ему достаются ревью пулл реквестов его команд, которым не хватает ревьюеров.
+// reassign = nil означает настройку команды
+func (us *userService) SetUserActive(ctx context.Context, userID string, isActive bool, reassign *bool) (*models.User, *models.ReassignmentReport, error) {
+	if isActive {
+		user, err := us.userRepo.SetUserActive(ctx, userID, isActive)
+		if err != nil {
+			return nil, nil, errors.New(NOT_FOUND)
+		}
+
+		// вернувшийся участник может взять ревью, которых не хватает пулл реквестам его команд
+		teams, err := us.userRepo.GetUserTeams(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(teams) > 0 {
+			if _, err := us.prService.BackfillReviewers(ctx, teams); err != nil {
+				return nil, nil, err
+			}
+		}
+		return user, nil, nil
+	}
+
+	user, err := us.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.New(NOT_FOUND)
+	}
+
+	if reassign == nil {
+		settings, err := us.teamRepo.GetTeamSettings(ctx, user.TeamName)
+		if err != nil {
+			return nil, nil, err
+		}
+		reassign = &settings.ReassignOnDeactivate
+	}
+	if !*reassign {
+		user, err := us.userRepo.SetUserActive(ctx, userID, false)
+		if err != nil {
+			return nil, nil, errors.New(NOT_FOUND)
+		}
+		return user, nil, nil
+	}
+
+	report, err := us.prService.PlanReassignments(ctx, userID, models.ReasonDeactivated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err = us.userRepo.DeactivateUser(ctx, userID, report.Reassigned, report.Rotation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, report, nil
+}
+
+func (us *userService) GetPRsByReviewer(ctx context.Context, userID string, statuses []models.Status) ([]models.PullRequestShort, error) {
+	for _, status := range statuses {
+		if !status.IsValid() {
+			return nil, errors.New(INVALID_INPUT)
+		}
+	}
+
+	prs, err := us.prRepo.GetPRsByReviewer(ctx, userID, statuses)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return prs, nil
+}
+
+func (us *userService) SetUserSkills(ctx context.Context, userID string, skills []string) (*models.User, error) {
+	if userID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	user, err := us.userRepo.SetUserSkills(ctx, userID, skills)
 	if err != nil {
 		return nil, errors.New(NOT_FOUND)
 	}
 	return user, nil
 }
 
-func (us *userService) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
-	prs, err := us.prRepo.GetPRsByReviewer(ctx, userID)
+func (us *userService) SetUserMaxOpenReviews(ctx context.Context, userID string, maxOpenReviews int) (*models.User, error) {
+	if userID == "" || maxOpenReviews < 0 {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	user, err := us.userRepo.SetUserMaxOpenReviews(ctx, userID, maxOpenReviews)
 	if err != nil {
 		return nil, errors.New(NOT_FOUND)
 	}
-	return prs, nil
+	return user, nil
+}
+
+func validUnavailability(period *models.Unavailability) bool {
+	return period.UserID != "" && !period.StartsAt.IsZero() && period.EndsAt.After(period.StartsAt)
+}
+
+func (us *userService) AddUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error) {
+	if !validUnavailability(period) {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	_, err := us.userRepo.GetUser(ctx, period.UserID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	return us.unavailabilityRepo.CreateUnavailability(ctx, period)
+}
+
+func (us *userService) GetUnavailability(ctx context.Context, userID string, includeExpired bool) ([]models.Unavailability, error) {
+	if userID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	_, err := us.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+
+	return us.unavailabilityRepo.GetUnavailabilityByUser(ctx, userID, includeExpired)
+}
+
+func (us *userService) UpdateUnavailability(ctx context.Context, period *models.Unavailability) (*models.Unavailability, error) {
+	if period.ID == 0 || !validUnavailability(period) {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	updated, err := us.unavailabilityRepo.UpdateUnavailability(ctx, period)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return updated, nil
+}
+
+func (us *userService) DeleteUnavailability(ctx context.Context, id int64, userID string) error {
+	if id == 0 || userID == "" {
+		return errors.New(INVALID_INPUT)
+	}
+
+	err := us.unavailabilityRepo.DeleteUnavailability(ctx, id, userID)
+	if err != nil {
+		return errors.New(NOT_FOUND)
+	}
+	return nil
+}
+
+func (us *userService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, errors.New(INVALID_INPUT)
+	}
+
+	user, err := us.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New(NOT_FOUND)
+	}
+	return user, nil
+}
+
+// ListUsers возвращает страницу пользователей. Курсор - закодированный id последнего
+// пользователя предыдущей страницы
+func (us *userService) ListUsers(ctx context.Context, filter models.UserListFilter, cursor string) (*models.UserPage, error) {
+	if filter.Limit < 0 {
+		return nil, errors.New(INVALID_INPUT)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = models.DefaultUserPageSize
+	}
+	filter.Limit = min(filter.Limit, models.MaxUserPageSize)
+
+	if cursor != "" {
+		afterID, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(afterID) == 0 {
+			return nil, errors.New(INVALID_CURSOR)
+		}
+		filter.AfterID = string(afterID)
+	}
+
+	// лишняя строка показывает, есть ли следующая страница
+	pageSize := filter.Limit
+	filter.Limit++
+	users, err := us.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.UserPage{
+		Users: users,
+	}
+	if page.Users == nil {
+		page.Users = []models.User{}
+	}
+	if len(users) > pageSize {
+		page.Users = users[:pageSize]
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(users[pageSize-1].ID))
+	}
+	return page, nil
+}
+
+// DeleteUser удаляет пользователя из бд. Автор DRAFT или OPEN пулл реквестов не удаляется,
+// пока они не закрыты. OPEN ревью передаются другим участникам его команды, а назначения,
+// для которых замены не нашлось, удаляются вместе с пользователем
+func (us *userService) DeleteUser(ctx context.Context, userID string) (*models.User, *models.ReassignmentReport, error) {
+	if userID == "" {
+		return nil, nil, errors.New(INVALID_INPUT)
+	}
+	if _, err := us.userRepo.GetUser(ctx, userID); err != nil {
+		return nil, nil, errors.New(NOT_FOUND)
+	}
+
+	open, err := us.prRepo.ListPRs(ctx, models.PRListFilter{
+		AuthorID: userID,
+		Statuses: []models.Status{models.StatusDraft, models.StatusOpen},
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(open) > 0 {
+		return nil, nil, errors.New(USER_HAS_OPEN_PRS)
+	}
+
+	report, err := us.prService.PlanReassignments(ctx, userID, models.ReasonDeleted)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := us.userRepo.DeleteUser(ctx, userID, report.Reassigned, report.Rotation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, report, nil
 }
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS team_rotation;
+DROP TABLE IF EXISTS team_settings;
\ No newline at end of file
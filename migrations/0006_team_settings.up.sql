@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_name TEXT PRIMARY KEY,
+    reviewer_strategy TEXT DEFAULT 'random' NOT NULL CHECK (reviewer_strategy IN ('random', 'round_robin', 'least_loaded')),
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS team_rotation (
+    team_name TEXT PRIMARY KEY,
+    last_reviewer_id TEXT NOT NULL,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_pull_requests_open ON pull_requests (id) WHERE status = 'OPEN';
@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS team_settings
+    DROP CONSTRAINT IF EXISTS team_settings_reviewer_count_check,
+    DROP COLUMN IF EXISTS min_reviewers,
+    DROP COLUMN IF EXISTS max_reviewers;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS min_reviewers INT DEFAULT 0 NOT NULL,
+    ADD COLUMN IF NOT EXISTS max_reviewers INT DEFAULT 2 NOT NULL;
+
+ALTER TABLE team_settings
+    ADD CONSTRAINT team_settings_reviewer_count_check
+    CHECK (min_reviewers >= 0 AND max_reviewers >= 1 AND min_reviewers <= max_reviewers);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS pr_reviewers DROP COLUMN IF EXISTS assigned_via;
+ALTER TABLE IF EXISTS pull_requests DROP COLUMN IF EXISTS codeowner_approval_required;
+DROP TABLE IF EXISTS codeowners;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS codeowners (
+    scope TEXT NOT NULL CHECK (scope IN ('TEAM', 'REPOSITORY')),
+    name TEXT NOT NULL,
+    content TEXT NOT NULL,
+    required BOOLEAN DEFAULT false NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, name)
+);
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS codeowner_approval_required BOOLEAN DEFAULT false NOT NULL;
+
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS assigned_via TEXT DEFAULT 'TEAM' NOT NULL;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_skills;
+ALTER TABLE IF EXISTS pull_requests DROP COLUMN IF EXISTS labels;
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS skills;
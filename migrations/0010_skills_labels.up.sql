@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS skills TEXT[] DEFAULT '{}' NOT NULL;
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS labels TEXT[] DEFAULT '{}' NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_skills ON users USING GIN (skills);
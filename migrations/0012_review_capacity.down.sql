@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS max_open_reviews;
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS max_open_reviews;
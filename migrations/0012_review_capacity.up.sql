@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS max_open_reviews INT DEFAULT 0 NOT NULL CHECK (max_open_reviews >= 0);
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS max_open_reviews INT DEFAULT 0 NOT NULL CHECK (max_open_reviews >= 0);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS pull_requests DROP COLUMN IF EXISTS force_merged;
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS required_approvals;
+DROP TABLE IF EXISTS pr_reviews;
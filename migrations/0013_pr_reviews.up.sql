@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS pr_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    decision TEXT NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    comment TEXT DEFAULT '' NOT NULL,
+    submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_pr_reviewer ON pr_reviews (pr_id, reviewer_id, submitted_at DESC);
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS required_approvals INT DEFAULT 0 NOT NULL CHECK (required_approvals >= 0);
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS force_merged BOOLEAN DEFAULT false NOT NULL;
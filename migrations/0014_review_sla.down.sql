@@ -0,0 +1,12 @@
+-- +migrate Down
+DROP TABLE IF EXISTS pr_reassignments;
+
+ALTER TABLE IF EXISTS team_settings
+    DROP CONSTRAINT IF EXISTS team_settings_business_hours_check,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS business_hours_end,
+    DROP COLUMN IF EXISTS business_hours_start,
+    DROP COLUMN IF EXISTS sla_business_hours_only,
+    DROP COLUMN IF EXISTS review_sla_hours;
+
+ALTER TABLE IF EXISTS pr_reviewers DROP COLUMN IF EXISTS assigned_at;
@@ -0,0 +1,24 @@
+-- +migrate Up
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ DEFAULT now() NOT NULL;
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS review_sla_hours INT DEFAULT 0 NOT NULL CHECK (review_sla_hours >= 0),
+    ADD COLUMN IF NOT EXISTS sla_business_hours_only BOOLEAN DEFAULT false NOT NULL,
+    ADD COLUMN IF NOT EXISTS business_hours_start INT DEFAULT 9 NOT NULL,
+    ADD COLUMN IF NOT EXISTS business_hours_end INT DEFAULT 18 NOT NULL,
+    ADD COLUMN IF NOT EXISTS timezone TEXT DEFAULT 'UTC' NOT NULL,
+    ADD CONSTRAINT team_settings_business_hours_check
+        CHECK (business_hours_start >= 0 AND business_hours_end <= 24 AND business_hours_start < business_hours_end);
+
+CREATE TABLE IF NOT EXISTS pr_reassignments (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id TEXT NOT NULL,
+    old_reviewer_id TEXT NOT NULL,
+    new_reviewer_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reassignments_pr ON pr_reassignments (pr_id);
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS reassign_on_deactivate;
@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS reassign_on_deactivate BOOLEAN DEFAULT false NOT NULL;
@@ -0,0 +1,9 @@
+-- +migrate Down
+UPDATE pull_requests SET status = 'OPEN' WHERE status IN ('DRAFT', 'CLOSED');
+
+ALTER TABLE IF EXISTS pull_requests
+    DROP COLUMN IF EXISTS changed_files,
+    DROP COLUMN IF EXISTS repository,
+    DROP COLUMN IF EXISTS closed_at,
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED'));
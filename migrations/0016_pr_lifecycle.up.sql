@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS repository TEXT DEFAULT '' NOT NULL,
+    ADD COLUMN IF NOT EXISTS changed_files TEXT[] DEFAULT '{}' NOT NULL;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_pull_requests_author;
+DROP INDEX IF EXISTS idx_pull_requests_merged_at;
+DROP INDEX IF EXISTS idx_pull_requests_created_at;
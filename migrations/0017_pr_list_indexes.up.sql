@@ -0,0 +1,4 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created_at ON pull_requests (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_merged_at ON pull_requests (merged_at, id) WHERE merged_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author ON pull_requests (author_id);
@@ -0,0 +1,22 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS team_rotation DROP CONSTRAINT IF EXISTS team_rotation_team_name_fkey;
+ALTER TABLE IF EXISTS team_rotation
+    ADD CONSTRAINT team_rotation_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS team_settings DROP CONSTRAINT IF EXISTS team_settings_team_name_fkey;
+ALTER TABLE IF EXISTS team_settings
+    ADD CONSTRAINT team_settings_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE IF EXISTS users
+    ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE;
+
+-- NOT NULL возвращается только если не осталось пользователей без команды
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM users WHERE team_name IS NULL) THEN
+        ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
+    END IF;
+END $$;
+-- +migrate StatementEnd
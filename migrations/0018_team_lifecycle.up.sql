@@ -0,0 +1,16 @@
+-- +migrate Up
+-- пользователь, удаленный из команды, остается в бд без команды:
+-- на него ссылаются пулл реквесты и история ревью
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE users
+    ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON UPDATE CASCADE ON DELETE SET NULL;
+
+ALTER TABLE team_settings DROP CONSTRAINT IF EXISTS team_settings_team_name_fkey;
+ALTER TABLE team_settings
+    ADD CONSTRAINT team_settings_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE team_rotation DROP CONSTRAINT IF EXISTS team_rotation_team_name_fkey;
+ALTER TABLE team_rotation
+    ADD CONSTRAINT team_rotation_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(name) ON UPDATE CASCADE ON DELETE CASCADE;
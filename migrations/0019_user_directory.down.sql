@@ -0,0 +1,27 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_team_name_id;
+
+ALTER TABLE IF EXISTS pr_reviews DROP CONSTRAINT IF EXISTS pr_reviews_reviewer_id_fkey;
+ALTER TABLE IF EXISTS pr_reviews
+    ADD CONSTRAINT pr_reviews_reviewer_id_fkey FOREIGN KEY (reviewer_id) REFERENCES users(id);
+
+ALTER TABLE IF EXISTS pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_reviewer_id_fkey;
+ALTER TABLE IF EXISTS pr_reviewers
+    ADD CONSTRAINT pr_reviewers_reviewer_id_fkey FOREIGN KEY (reviewer_id) REFERENCES users(id);
+
+ALTER TABLE IF EXISTS pull_requests DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+ALTER TABLE IF EXISTS pull_requests
+    ADD CONSTRAINT pull_requests_author_id_fkey FOREIGN KEY (author_id) REFERENCES users(id);
+
+-- NOT NULL возвращается только если не осталось записей удаленных пользователей
+-- +migrate StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pr_reviews WHERE reviewer_id IS NULL) THEN
+        ALTER TABLE pr_reviews ALTER COLUMN reviewer_id SET NOT NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pull_requests WHERE author_id IS NULL) THEN
+        ALTER TABLE pull_requests ALTER COLUMN author_id SET NOT NULL;
+    END IF;
+END $$;
+-- +migrate StatementEnd
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- удаленный пользователь пропадает из истории: у его закрытых пулл реквестов и решений ревью
+-- ссылка обнуляется, назначения на ревью удаляются. OPEN ревью сервис переназначает до удаления
+ALTER TABLE pull_requests ALTER COLUMN author_id DROP NOT NULL;
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_author_id_fkey FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_reviewer_id_fkey;
+ALTER TABLE pr_reviewers
+    ADD CONSTRAINT pr_reviewers_reviewer_id_fkey FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE pr_reviews ALTER COLUMN reviewer_id DROP NOT NULL;
+ALTER TABLE pr_reviews DROP CONSTRAINT IF EXISTS pr_reviews_reviewer_id_fkey;
+ALTER TABLE pr_reviews
+    ADD CONSTRAINT pr_reviews_reviewer_id_fkey FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_team_name_id ON users (team_name, id);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TRIGGER IF EXISTS users_primary_team_member ON users;
+DROP FUNCTION IF EXISTS sync_primary_team_member();
+DROP TABLE IF EXISTS team_members;
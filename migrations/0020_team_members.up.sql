@@ -0,0 +1,45 @@
+-- +migrate Up
+-- участие в командах. users.team_name остается основной командой пользователя: по ней берутся
+-- настройки и лимит ревью, а строку основной команды здесь поддерживает триггер
+CREATE TABLE IF NOT EXISTS team_members (
+    team_name TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    is_primary BOOLEAN DEFAULT false NOT NULL,
+    joined_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    PRIMARY KEY (team_name, user_id),
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_members_primary ON team_members (user_id) WHERE is_primary;
+
+INSERT INTO team_members (team_name, user_id, is_primary)
+SELECT team_name, id, true
+FROM users
+WHERE team_name IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION sync_primary_team_member() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.team_name IS NOT DISTINCT FROM OLD.team_name THEN
+        RETURN NEW;
+    END IF;
+
+    DELETE FROM team_members WHERE user_id = NEW.id AND is_primary;
+    IF NEW.team_name IS NOT NULL THEN
+        -- дополнительное участие в новой основной команде становится основным
+        INSERT INTO team_members (team_name, user_id, is_primary)
+        VALUES (NEW.team_name, NEW.id, true)
+        ON CONFLICT (team_name, user_id) DO UPDATE SET is_primary = true;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+DROP TRIGGER IF EXISTS users_primary_team_member ON users;
+CREATE TRIGGER users_primary_team_member
+    AFTER INSERT OR UPDATE OF team_name ON users
+    FOR EACH ROW EXECUTE FUNCTION sync_primary_team_member();
@@ -0,0 +1,6 @@
+-- +migrate Down
+-- назначения через эскалацию остаются назначениями команды
+UPDATE pr_reviewers SET assigned_via = 'TEAM' WHERE assigned_via = 'ESCALATION';
+
+DROP INDEX IF EXISTS idx_teams_parent_team;
+ALTER TABLE IF EXISTS teams DROP COLUMN IF EXISTS parent_team;
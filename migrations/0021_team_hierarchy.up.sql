@@ -0,0 +1,8 @@
+-- +migrate Up
+-- родительская команда: к ней и к ее дочерним командам эскалируется подбор ревьюеров,
+-- когда в своей команде кандидатов не хватает
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS parent_team TEXT REFERENCES teams(name) ON UPDATE CASCADE ON DELETE SET NULL,
+    ADD CONSTRAINT teams_parent_team_check CHECK (parent_team <> name);
+
+CREATE INDEX IF NOT EXISTS idx_teams_parent_team ON teams (parent_team) WHERE parent_team IS NOT NULL;
@@ -0,0 +1,6 @@
+-- +migrate Down
+UPDATE pr_reviewers SET assigned_via = 'TEAM' WHERE assigned_via = 'POOL';
+
+DROP TABLE IF EXISTS team_reviewer_pools;
+DROP TABLE IF EXISTS reviewer_pool_members;
+DROP TABLE IF EXISTS reviewer_pools;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- именованные пулы ревьюеров из любых команд. Команда подключает пул как дополнительный
+-- источник кандидатов (SUPPLEMENTARY) или как резервный, когда своих кандидатов не хватило (FALLBACK)
+CREATE TABLE IF NOT EXISTS reviewer_pools (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS reviewer_pool_members (
+    pool_name TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    PRIMARY KEY (pool_name, user_id),
+    FOREIGN KEY (pool_name) REFERENCES reviewer_pools(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_pool_members_user_id ON reviewer_pool_members (user_id);
+
+CREATE TABLE IF NOT EXISTS team_reviewer_pools (
+    team_name TEXT NOT NULL,
+    pool_name TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('SUPPLEMENTARY', 'FALLBACK')),
+    PRIMARY KEY (team_name, pool_name),
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (pool_name) REFERENCES reviewer_pools(name) ON UPDATE CASCADE ON DELETE CASCADE
+);
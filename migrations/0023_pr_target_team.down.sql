@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_pull_requests_target_team;
+ALTER TABLE IF EXISTS pull_requests
+    DROP COLUMN IF EXISTS author_team_review,
+    DROP COLUMN IF EXISTS target_team;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- пулл реквест в кодовую базу другой команды ревьюит она. target_team хранится, только если
+-- отличается от команды автора на момент создания, поэтому непустое значение означает вклад в чужую команду
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS target_team TEXT REFERENCES teams(name) ON UPDATE CASCADE ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS author_team_review BOOLEAN DEFAULT false NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_target_team ON pull_requests (target_team) WHERE target_team IS NOT NULL;
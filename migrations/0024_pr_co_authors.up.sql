@@ -0,0 +1,11 @@
+-- +migrate Up
+-- соавторы пулл реквеста, например при парном программировании. Основной автор остается в author_id
+CREATE TABLE IF NOT EXISTS pr_co_authors (
+    pr_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    PRIMARY KEY (pr_id, user_id),
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_co_authors_user_id ON pr_co_authors (user_id);
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS pull_requests
+    DROP COLUMN IF EXISTS required_reviewers;
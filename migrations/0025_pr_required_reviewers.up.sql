@@ -0,0 +1,15 @@
+-- +migrate Up
+-- сколько ревьюеров должно быть у пулл реквеста. Если назначено меньше, недостающие добираются,
+-- когда в команде появляются новые или вернувшиеся участники
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS required_reviewers INT DEFAULT 0 NOT NULL;
+
+-- уже открытым пулл реквестам требуется max_reviewers ревьюящей команды
+-- и запрошенный ревьюер из команды автора
+UPDATE pull_requests p
+SET required_reviewers = COALESCE((
+    SELECT ts.max_reviewers
+    FROM team_settings ts
+    WHERE ts.team_name = COALESCE(p.target_team, (SELECT a.team_name FROM users a WHERE a.id = p.author_id))
+), 2) + CASE WHEN p.target_team IS NOT NULL AND p.author_team_review THEN 1 ELSE 0 END
+WHERE p.status <> 'DRAFT';
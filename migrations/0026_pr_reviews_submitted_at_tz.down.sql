@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE IF EXISTS pr_reviews
+    ALTER COLUMN submitted_at TYPE TIMESTAMP,
+    ALTER COLUMN submitted_at SET DEFAULT CURRENT_TIMESTAMP;
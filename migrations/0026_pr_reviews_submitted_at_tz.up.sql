@@ -0,0 +1,6 @@
+-- +migrate Up
+-- submitted_at сравнивается с pr_reviewers.assigned_at, поэтому хранится с часовым поясом, как и он.
+-- Старые значения записаны CURRENT_TIMESTAMP в часовом поясе сессии, в нем же и интерпретируются
+ALTER TABLE pr_reviews
+    ALTER COLUMN submitted_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN submitted_at SET DEFAULT now();